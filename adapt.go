@@ -0,0 +1,25 @@
+package lazy
+
+// MustFetch adapts a fetch function that can't fail to the signature used by Map and LazyMap.
+func MustFetch[K comparable, V any](fn func(K) V) func(K) (V, error) {
+	return func(k K) (V, error) { return fn(k), nil }
+}
+
+// GetNoErr is like Get for a fetch function that can't fail.
+// The map's default options still apply. Any error Get returns is discarded, and whatever value
+// came with it, usually the zero value, is returned. Even though fn can't fail, that covers
+// ErrRecursiveLoad when fn loads its own key under WithRecursionGuard, a *PanicError if fn
+// panics under WithRecover,
+// ErrLoadTimeout under WithTimeout, errors from a WithFallback loader and misconfigurations such
+// as WithRefreshAhead without a time-based Expiry. Use Get if any of those can happen.
+func (lm *LazyMap[K, V]) GetNoErr(key K, fn func(K) V) V {
+	v, _ := lm.Get(key, MustFetch(fn))
+	return v
+}
+
+// WithDefaultFetch returns an Option that sets the fetch function used when Map or Get is
+// called with a nil one, for caches that always load the same way. A fetch function passed
+// to the call still takes precedence.
+func WithDefaultFetch[K comparable, V any](fetch func(K) (V, error)) Option[K, V] {
+	return func(a *args[K, V]) { a.defaultFetch = fetch }
+}
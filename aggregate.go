@@ -0,0 +1,54 @@
+package lazy
+
+import "cmp"
+
+// Number is satisfied by the built-in integer and floating-point types, and types based on them.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// SumValues returns the sum of every loaded, non-errored value in lm. Like ApproxSize, it reads
+// the values under the read lock without counting a use.
+func SumValues[K comparable, V Number](lm *LazyMap[K, V]) V {
+	var sum V
+	lm.eachLoaded(func(v V) { sum += v })
+	return sum
+}
+
+// MaxValue returns the largest loaded, non-errored value in lm, or false if there are none.
+// It doesn't count as a use.
+func MaxValue[K comparable, V cmp.Ordered](lm *LazyMap[K, V]) (V, bool) {
+	return extremeValue(lm, 1)
+}
+
+// MinValue returns the smallest loaded, non-errored value in lm, or false if there are none.
+// It doesn't count as a use.
+func MinValue[K comparable, V cmp.Ordered](lm *LazyMap[K, V]) (V, bool) {
+	return extremeValue(lm, -1)
+}
+
+// extremeValue returns the value v for which cmp.Compare(v, other) == sign for every other value.
+func extremeValue[K comparable, V cmp.Ordered](lm *LazyMap[K, V], sign int) (V, bool) {
+	var best V
+	found := false
+	lm.eachLoaded(func(v V) {
+		if !found || cmp.Compare(v, best) == sign {
+			best = v
+			found = true
+		}
+	})
+	return best, found
+}
+
+// eachLoaded calls fn with every loaded, non-errored value while holding the read lock.
+func (lm *LazyMap[K, V]) eachLoaded(fn func(V)) {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+	for _, lv := range lm.m {
+		if v, ok, err := lv.Value(); ok && err == nil {
+			fn(v)
+		}
+	}
+}
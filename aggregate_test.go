@@ -0,0 +1,32 @@
+package lazy_test
+
+import (
+	"errors"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestAggregateValues(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	if _, ok := lazy.MaxValue(lm); ok {
+		t.Fatal("Expected no maximum for an empty map")
+	}
+	if sum := lazy.SumValues(lm); sum != 0 {
+		t.Fatalf("Expected an empty sum of 0, got %d", sum)
+	}
+
+	lm.SetMany(map[string]int{"a": 3, "b": -2, "c": 10})
+	// Errored entries are skipped.
+	_, _ = lm.Get("d", func(string) (int, error) { return 100, errors.New("boom") })
+
+	if sum := lazy.SumValues(lm); sum != 11 {
+		t.Fatalf("Expected a sum of 11, got %d", sum)
+	}
+	if v, ok := lazy.MaxValue(lm); !ok || v != 10 {
+		t.Fatalf("Expected a maximum of 10, got %v %v", v, ok)
+	}
+	if v, ok := lazy.MinValue(lm); !ok || v != -2 {
+		t.Fatalf("Expected a minimum of -2, got %v %v", v, ok)
+	}
+}
@@ -0,0 +1,62 @@
+package lazy
+
+// Cache is a minimal cache interface, for code that wants to be written against an abstraction
+// rather than this package. ReadThrough adapts a LazyMap to it.
+type Cache[K comparable, V any] interface {
+	// Get returns the value for key and whether it was found.
+	Get(key K) (V, bool)
+	// Set stores value for key, replacing any existing value.
+	Set(key K, value V)
+	// Delete removes key, if present.
+	Delete(key K)
+	// Len returns the number of entries.
+	Len() int
+}
+
+var _ Cache[string, int] = (*ReadThrough[string, int])(nil)
+
+// ReadThrough adapts a LazyMap to the Cache interface, which LazyMap can't implement itself
+// because its Get takes a fetch function. Get loads missing keys with the fetch function given
+// to NewReadThrough, or, if that is nil, only returns values already cached.
+type ReadThrough[K comparable, V any] struct {
+	lm    *LazyMap[K, V]
+	fetch func(K) (V, error)
+}
+
+// NewReadThrough returns a Cache backed by lm, loading missing keys with fetch if it isn't nil.
+func NewReadThrough[K comparable, V any](lm *LazyMap[K, V], fetch func(K) (V, error)) *ReadThrough[K, V] {
+	return &ReadThrough[K, V]{lm: lm, fetch: fetch}
+}
+
+// Get returns the value for key. Without a fetch function it only looks in the cache, using
+// GetIfPresent rather than a DontFetch Get so that misses don't leave empty entries counted by
+// Len. With one, a missing key is loaded, and a failed load, or a cached failure from an earlier
+// one, is reported as not found.
+func (c *ReadThrough[K, V]) Get(key K) (V, bool) {
+	if c.fetch == nil {
+		return c.lm.GetIfPresent(key)
+	}
+	// A cached error is served by Get without one, so check the entry itself as well.
+	var lv *Value[V]
+	v, err := c.lm.Get(key, c.fetch, WithValueDest[K, V](&lv))
+	if err != nil || lv != nil && lv.Err() != nil {
+		var zero V
+		return zero, false
+	}
+	return v, true
+}
+
+// Set stores value for key, replacing any loaded value; see LazyMap.Put.
+func (c *ReadThrough[K, V]) Set(key K, value V) {
+	c.lm.Put(key, value)
+}
+
+// Delete removes key; see LazyMap.Delete.
+func (c *ReadThrough[K, V]) Delete(key K) {
+	c.lm.Delete(key)
+}
+
+// Len returns the number of entries in the map.
+func (c *ReadThrough[K, V]) Len() int {
+	return c.lm.Len()
+}
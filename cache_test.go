@@ -0,0 +1,51 @@
+package lazy_test
+
+import (
+	"errors"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestReadThroughCache(t *testing.T) {
+	var c lazy.Cache[string, int] = lazy.NewReadThrough(lazy.NewLazyMap[string, int](), nil)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("Expected a miss before Set")
+	}
+	c.Set("a", 1)
+	c.Set("a", 2)
+	if v, ok := c.Get("a"); !ok || v != 2 {
+		t.Fatalf("Expected 2, got %v %v", v, ok)
+	}
+	if c.Len() != 1 {
+		t.Fatalf("Expected 1 entry, got %d", c.Len())
+	}
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("Expected a miss after Delete")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("Expected 0 entries, got %d", c.Len())
+	}
+}
+
+func TestReadThroughFetch(t *testing.T) {
+	fetch := func(k string) (int, error) {
+		if k == "missing" {
+			return 0, errors.New("no such key")
+		}
+		return len(k), nil
+	}
+	var c lazy.Cache[string, int] = lazy.NewReadThrough(lazy.NewLazyMap[string, int](), fetch)
+
+	if v, ok := c.Get("abc"); !ok || v != 3 {
+		t.Fatalf("Expected the fetched value 3, got %v %v", v, ok)
+	}
+	for i := 0; i < 2; i++ {
+		// The second call is served the cached error rather than fetching again.
+		if v, ok := c.Get("missing"); ok {
+			t.Fatalf("call %d: Expected a failed fetch to be a miss, got %v", i+1, v)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package lazy
+
+import (
+	"sync"
+	"time"
+)
+
+// TimeSource tells the time. Values and expiry policies use it in place of time.Now, so that
+// tests can control time with a FakeClock instead of sleeping.
+type TimeSource interface {
+	Now() time.Time
+}
+
+// WithTimeSource returns an Option that makes the Values a map creates read the time from ts:
+// their CreatedAt and LastAccess, and the time-based Expiry policies, WithNegativeCache,
+// WithCoalesceWindow and WithRefreshAhead that judge them, and the times in the removal log.
+// Timings reported to Metrics still use the system clock. A nil ts means the system clock.
+func WithTimeSource[K comparable, V any](ts TimeSource) Option[K, V] {
+	return func(a *args[K, V]) { a.clock = ts }
+}
+
+// now returns the current time according to the Value's TimeSource.
+func (l *Value[T]) now() time.Time {
+	if e := l.ext.Load(); e != nil && e.clock != nil {
+		return e.clock.Now()
+	}
+	return time.Now()
+}
+
+// now returns the current time according to the map's TimeSource.
+func (a *args[K, V]) now() time.Time {
+	if a.clock != nil {
+		return a.clock.Now()
+	}
+	return time.Now()
+}
+
+// FakeClock is a TimeSource that only moves when told to, for tests.
+// Safe for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock reading start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
@@ -0,0 +1,23 @@
+package lazy
+
+import "time"
+
+// WithCoalesceWindow returns an Option that limits Refresh to one fetch per key every d.
+// A Refresh within d of the key's last successful load, or while a load of it is still in
+// progress, is served from the cache like a normal lookup instead, protecting the backend
+// from refresh storms. Refreshes of values cached with an error aren't held back.
+func WithCoalesceWindow[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(a *args[K, V]) { a.coalesceWindow = d }
+}
+
+// coalesced reports whether a Refresh of lv should be served by lv rather than fetching again.
+func (a *args[K, V]) coalesced(lv *Value[V]) bool {
+	if a.coalesceWindow <= 0 {
+		return false
+	}
+	if !lv.IsLoaded() {
+		// Join the load in progress rather than starting another.
+		return true
+	}
+	return lv.loadedOK() && lv.now().Sub(lv.CreatedAt()) < a.coalesceWindow
+}
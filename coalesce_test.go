@@ -0,0 +1,49 @@
+package lazy_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestWithCoalesceWindow(t *testing.T) {
+	clock := lazy.NewFakeClock(time.Now())
+	lm := lazy.NewLazyMap[string, int64](
+		lazy.WithTimeSource[string, int64](clock),
+		lazy.WithCoalesceWindow[string, int64](50*time.Millisecond),
+	)
+	var fetches atomic.Int64
+	fetch := func(string) (int64, error) { return fetches.Add(1), nil }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := lm.Get("k", fetch, lazy.Refresh[string, int64]()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	for i := 0; i < 20; i++ {
+		if v := Must(lm.Get("k", fetch, lazy.Refresh[string, int64]())); v != 1 {
+			t.Fatalf("Expected the coalesced value 1, got %d", v)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("Expected 1 fetch within the window, got %d", n)
+	}
+
+	clock.Advance(49 * time.Millisecond)
+	if v := Must(lm.Get("k", fetch, lazy.Refresh[string, int64]())); v != 1 {
+		t.Fatalf("Expected the coalesced value 1 just inside the window, got %d", v)
+	}
+	clock.Advance(time.Millisecond)
+	if v := Must(lm.Get("k", fetch, lazy.Refresh[string, int64]())); v != 2 {
+		t.Fatalf("Expected a refetch once the window passed, got %d", v)
+	}
+}
@@ -0,0 +1,242 @@
+package lazy_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestMapContextCancelDoesNotFailOthers(t *testing.T) {
+	m := make(map[string]*lazy.Value[int])
+	var mu sync.RWMutex
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var calls atomic.Int32
+	fetch := func(ctx context.Context, _ string) (int, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		select {
+		case <-release:
+			return 42, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	// The first caller starts the fetch, then gives up.
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := lazy.MapContext(ctx, &m, &mu, "k", fetch)
+		firstErr <- err
+	}()
+	<-started
+
+	var wg sync.WaitGroup
+	results := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := lazy.MapContext(context.Background(), &m, &mu, "k", fetch)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- v
+		}()
+	}
+
+	// Give the other callers time to join the fetch before the first gives up.
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-firstErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("canceled caller got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("canceled caller didn't return promptly")
+	}
+
+	close(release)
+	wg.Wait()
+	close(results)
+	for v := range results {
+		if v != 42 {
+			t.Fatalf("got %d", v)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("fetch called %d times", n)
+	}
+}
+
+func TestMapContextAbandonedFetchStoresLateResult(t *testing.T) {
+	m := make(map[string]*lazy.Value[int])
+	var mu sync.RWMutex
+	var lv *lazy.Value[int]
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := lazy.MapContext(ctx, &m, &mu, "k", func(context.Context, string) (int, error) {
+		time.Sleep(30 * time.Millisecond)
+		return 7, nil
+	}, lazy.WithValueDest[string, int](&lv))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err=%v", err)
+	}
+	if v, err := lv.Wait(context.Background()); err != nil || v != 7 {
+		t.Fatalf("Wait got %v %v", v, err)
+	}
+	v, err := lazy.Map(&m, &mu, "k", nil, lazy.DontFetch[string, int](), lazy.MustBeCached[string, int]())
+	if err != nil || v != 7 {
+		t.Fatalf("late result got %v %v", v, err)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	t.Run("keeps loading in the background", func(t *testing.T) {
+		var fetches atomic.Int32
+		release := make(chan struct{})
+		lm := lazy.NewLazyMap[string, int](lazy.WithTimeout[string, int](50 * time.Millisecond))
+		fetch := func(string) (int, error) {
+			fetches.Add(1)
+			<-release
+			return 7, nil
+		}
+
+		if _, err := lm.Get("k", fetch); !errors.Is(err, lazy.ErrLoadTimeout) {
+			t.Fatalf("first err = %v, want ErrLoadTimeout", err)
+		}
+		// A caller arriving while the load is still running waits on it rather than refetching.
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			close(release)
+		}()
+		if v, err := lm.Get("k", fetch); err != nil || v != 7 {
+			t.Fatalf("second got %v %v", v, err)
+		}
+		if n := fetches.Load(); n != 1 {
+			t.Fatalf("fetches = %d, want 1", n)
+		}
+		// Cache hits never time out.
+		if v, err := lm.Get("k", func(string) (int, error) { select {} }); err != nil || v != 7 {
+			t.Fatalf("hit got %v %v", v, err)
+		}
+	})
+
+	t.Run("abandon", func(t *testing.T) {
+		var fetches atomic.Int32
+		lm := lazy.NewLazyMap[string, int](
+			lazy.WithTimeout[string, int](20*time.Millisecond),
+			lazy.AbandonOnTimeout[string, int](),
+		)
+		slow := func(string) (int, error) {
+			fetches.Add(1)
+			time.Sleep(100 * time.Millisecond)
+			return 0, errors.New("too slow")
+		}
+
+		if _, err := lm.Get("k", slow); !errors.Is(err, lazy.ErrLoadTimeout) {
+			t.Fatalf("first err = %v, want ErrLoadTimeout", err)
+		}
+		// The abandoned load's failure isn't cached, so a later call starts afresh.
+		time.Sleep(150 * time.Millisecond)
+		if v, err := lm.Get("k", func(string) (int, error) { fetches.Add(1); return 3, nil }); err != nil || v != 3 {
+			t.Fatalf("after abandon got %v %v", v, err)
+		}
+		if n := fetches.Load(); n != 2 {
+			t.Fatalf("fetches = %d, want 2", n)
+		}
+	})
+}
+
+func TestLazyMapGetContextScope(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	var calls atomic.Int32
+	fetch := func(ctx context.Context, _ string) (int, error) {
+		if ctx == nil {
+			t.Error("fetch got a nil context")
+		}
+		return int(calls.Add(1)), nil
+	}
+
+	req1, end1 := context.WithCancel(context.Background())
+	if v := Must(lm.GetContext(req1, "user", fetch)); v != 1 {
+		t.Fatalf("first got %d", v)
+	}
+	// Still valid while the request that loaded it is live, including for other callers.
+	if v := Must(lm.GetContext(context.Background(), "user", fetch)); v != 1 {
+		t.Fatalf("during request got %d", v)
+	}
+	if v, ok := lm.GetIfPresent("user"); !ok || v != 1 {
+		t.Fatalf("GetIfPresent during request got %v %v", v, ok)
+	}
+
+	end1()
+	if _, ok := lm.GetIfPresent("user"); ok {
+		t.Fatal("entry still present after its request ended")
+	}
+	req2, end2 := context.WithCancel(context.Background())
+	defer end2()
+	if v := Must(lm.GetContext(req2, "user", fetch)); v != 2 {
+		t.Fatalf("after request ended got %d", v)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("fetches = %d, want 2", n)
+	}
+}
+
+func TestValueLoadContextLoaded(t *testing.T) {
+	var v lazy.Value[int]
+	v.Set(7)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err := v.LoadContext(ctx, func(context.Context) (int, error) {
+		t.Fatal("fn called for a loaded value")
+		return 0, nil
+	})
+	if err != nil || got != 7 {
+		t.Fatalf("got %v %v", got, err)
+	}
+}
+
+func TestValueLoadContextCanceled(t *testing.T) {
+	var v lazy.Value[int]
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := v.LoadContext(ctx, func(context.Context) (int, error) { return 1, nil }); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if v.IsLoaded() {
+		t.Fatal("a canceled call cached a result")
+	}
+
+	// A caller that gives up mid-load doesn't poison the Value for later callers.
+	ctx, cancel = context.WithCancel(context.Background())
+	started := make(chan struct{})
+	errc := make(chan error, 1)
+	go func() {
+		_, err := v.LoadContext(ctx, func(ctx context.Context) (int, error) {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		errc <- err
+	}()
+	<-started
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	got, err := v.LoadContext(context.Background(), func(context.Context) (int, error) { return 2, nil })
+	if err != nil || got != 2 {
+		t.Fatalf("expected a later caller to load 2, got %v %v", got, err)
+	}
+}
@@ -0,0 +1,64 @@
+package lazy
+
+import (
+	"maps"
+	"sync/atomic"
+)
+
+// WithCopyOnWrite returns an Option that makes a LazyMap serve cache hits without locking, for
+// read-heavy caches that rarely change. The map keeps an immutable copy of its entries behind an
+// atomic pointer: Get looks keys up in the copy, and every change to the set of entries, such
+// as an insert, eviction or removal, copies the map again while holding the write lock. Reads
+// never contend, but each change costs O(n). Loading a value doesn't change the set of entries,
+// so the usual once-per-key loading is unaffected. Only calls to Get without extra options use
+// the copy; anything else, and every miss, goes through the lock as usual. It has no effect on
+// Map used directly, or on a LazyMap not created with NewLazyMap.
+func WithCopyOnWrite[K comparable, V any]() Option[K, V] {
+	return func(a *args[K, V]) { a.copyOnWrite = true }
+}
+
+// cowMap holds the immutable copy of a LazyMap's entries published under WithCopyOnWrite.
+type cowMap[K comparable, V any] struct {
+	entries atomic.Pointer[map[K]*Value[V]]
+}
+
+// withCOW returns an Option that publishes the map's entries to c. NewLazyMap adds it when
+// WithCopyOnWrite is set, so that each map has a copy of its own.
+func withCOW[K comparable, V any](c *cowMap[K, V]) Option[K, V] {
+	return func(a *args[K, V]) { a.cow = c }
+}
+
+// republish replaces the published copy with a copy of m. It must be called with the write
+// lock held, after every change to m, so that copies are published in the order of the changes.
+func (a *args[K, V]) republish(m map[K]*Value[V]) {
+	if a.cow == nil {
+		return
+	}
+	snapshot := maps.Clone(m)
+	a.cow.entries.Store(&snapshot)
+}
+
+// lookupCOW serves a cache hit for key from the published copy, doing the bookkeeping Map does
+// for a hit. It reports false if the key has to go through Map: it is missing, not loaded yet,
+// holds an error or has expired.
+func (lm *LazyMap[K, V]) lookupCOW(key K, fetch func(K) (V, error), a *args[K, V]) (V, bool) {
+	var zero V
+	entries := a.cow.entries.Load()
+	if entries == nil {
+		return zero, false
+	}
+	lv, ok := (*entries)[key]
+	if !ok || !lv.loadedOK() || a.isExpired(lv) {
+		return zero, false
+	}
+	v, _ := lv.Peek()
+	if fetch == nil {
+		fetch = a.defaultFetch
+	}
+	if a.evictionPolicy != nil {
+		a.evictionPolicy.Access(key)
+	}
+	a.hit(key)
+	maybeRefreshAhead(&lm.m, &lm.mu, key, lv, fetch, a)
+	return v, true
+}
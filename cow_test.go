@@ -0,0 +1,64 @@
+package lazy_test
+
+import (
+	"sync"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestWithCopyOnWrite(t *testing.T) {
+	lm := lazy.NewLazyMap[int, int](lazy.WithCopyOnWrite[int, int](), lazy.MaxSize[int, int](50))
+	double := func(k int) (int, error) { return k * 2, nil }
+
+	if v := Must(lm.Get(1, double)); v != 2 {
+		t.Fatalf("Expected 2, got %d", v)
+	}
+	// Changes are visible to the lock-free path straight away.
+	lm.Delete(1)
+	if v := Must(lm.Get(1, func(k int) (int, error) { return -1, nil })); v != -1 {
+		t.Fatalf("Expected a deleted key to be fetched again, got %d", v)
+	}
+	lm.Put(1, 2)
+	if v := Must(lm.Get(1, double)); v != 2 {
+		t.Fatalf("Expected the put value 2, got %d", v)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				k := i % 100
+				v, err := lm.Get(k, double)
+				if err != nil || v != k*2 {
+					t.Errorf("Get(%d) = %d, %v; want %d", k, v, err, k*2)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		switch i % 3 {
+		case 0:
+			lm.Delete(i % 100)
+		case 1:
+			lm.Put(i%100, (i%100)*2)
+		case 2:
+			lm.RemoveWhere(func(k int, _ *lazy.Value[int]) bool { return k == i%100 })
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if n := lm.Len(); n > 50 {
+		t.Fatalf("Expected MaxSize to hold, got %d entries", n)
+	}
+}
@@ -0,0 +1,42 @@
+package lazy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// String describes the value's state for debugging: whether it is loaded, its value or error,
+// how many times it has been used, and when it was loaded. Values are formatted with %v, so
+// a value implementing fmt.Stringer is shown using its String method.
+// It doesn't count as a use.
+func (l *Value[T]) String() string {
+	r := l.val.Load()
+	if r == nil {
+		return fmt.Sprintf("<not loaded> uses=%d", l.uses.Load())
+	}
+	if r.err != nil {
+		return fmt.Sprintf("error=%v uses=%d created=%s", r.err, l.uses.Load(), r.created().Format(time.RFC3339Nano))
+	}
+	return fmt.Sprintf("value=%v uses=%d created=%s", r.value, l.uses.Load(), r.created().Format(time.RFC3339Nano))
+}
+
+// Dump returns a summary of every entry in the map, one "key: state" line per entry in the
+// format of Value.String, sorted for stable output. It is meant for logging while debugging.
+// It doesn't count as a use of any entry.
+func (lm *LazyMap[K, V]) Dump() string {
+	lm.mu.RLock()
+	lines := make([]string, 0, len(lm.m))
+	for k, lv := range lm.m {
+		lines = append(lines, fmt.Sprintf("%v: %s", k, lv))
+	}
+	lm.mu.RUnlock()
+	slices.Sort(lines)
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package lazy_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+type celsius float64
+
+func (c celsius) String() string { return "warm" }
+
+func TestLazyMapDump(t *testing.T) {
+	lm := lazy.NewLazyMap[string, celsius]()
+	lm.Set("loaded", 21)
+	_, _ = lm.Get("failed", func(string) (celsius, error) { return 0, errors.New("sensor offline") })
+	// DontFetch on a missing key leaves an unloaded entry behind.
+	_, _ = lm.Get("unloaded", nil, lazy.DontFetch[string, celsius]())
+
+	dump := lm.Dump()
+	lines := strings.Split(strings.TrimSuffix(dump, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("dump:\n%s", dump)
+	}
+	for _, want := range []string{
+		"failed: error=sensor offline",
+		"loaded: value=warm uses=",
+		"unloaded: <not loaded>",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump missing %q:\n%s", want, dump)
+		}
+	}
+}
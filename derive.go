@@ -0,0 +1,26 @@
+package lazy
+
+// MapValue returns a Value derived from src by f. Nothing is evaluated until the derived Value
+// is loaded; its Load ignores the function passed to it, so Load(nil) will do. src is then
+// loaded if needed, with its own Load(nil), and f is applied to the result. The derived Value
+// caches its own result, so f runs at most once. If src holds an error, f isn't called and the
+// error is cached in the derived Value. If src isn't loaded, and isn't derived itself, loading
+// the derived Value returns ErrValueNotCached without caching it, so it can be loaded once src is.
+func MapValue[T, U any](src *Value[T], f func(T) (U, error)) *Value[U] {
+	lv := &Value[U]{}
+	lv.ext.Store(&valueExt[U]{source: func() (U, error) {
+		v, err := src.Load(nil)
+		if err != nil {
+			var zero U
+			return zero, err
+		}
+		return f(v)
+	}})
+	return lv
+}
+
+// unready reports whether err is a derived Value's source finding its own source not loaded,
+// which isn't cached so that a later load can succeed; see MapValue.
+func (l *Value[T]) unready(err error) bool {
+	return err == ErrValueNotCached && l.source() != nil
+}
@@ -0,0 +1,87 @@
+package lazy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestMapValue(t *testing.T) {
+	var src lazy.Value[string]
+	calls := 0
+	length := lazy.MapValue(&src, func(s string) (int, error) {
+		calls++
+		return len(s), nil
+	})
+	if length.IsLoaded() || calls != 0 {
+		t.Fatal("derived value evaluated eagerly")
+	}
+
+	Must(src.Load(func() (string, error) { return "hello", nil }))
+	if calls != 0 {
+		t.Fatal("derived value evaluated when src loaded")
+	}
+	for i := 0; i < 3; i++ {
+		if n := Must(length.Load(nil)); n != 5 {
+			t.Fatalf("length = %d", n)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("f called %d times", calls)
+	}
+
+	// Derived values can be chained, loading through to the source.
+	double := lazy.MapValue(length, func(n int) (int, error) { return n * 2, nil })
+	if n := Must(double.Load(nil)); n != 10 {
+		t.Fatalf("double = %d", n)
+	}
+}
+
+func TestMapValueError(t *testing.T) {
+	var src lazy.Value[string]
+	boom := errors.New("boom")
+	_, _ = src.Load(func() (string, error) { return "", boom })
+	derived := lazy.MapValue(&src, func(s string) (int, error) {
+		t.Fatal("f called for a failed src")
+		return 0, nil
+	})
+	if _, err := derived.Load(nil); !errors.Is(err, boom) {
+		t.Fatalf("err = %v", err)
+	}
+	if !derived.HasError() {
+		t.Fatal("error not cached in derived value")
+	}
+}
+
+func TestMapValueBeforeSrcLoaded(t *testing.T) {
+	var src lazy.Value[string]
+	length := lazy.MapValue(&src, func(s string) (int, error) { return len(s), nil })
+	double := lazy.MapValue(length, func(n int) (int, error) { return n * 2, nil })
+
+	if _, err := double.Load(nil); !errors.Is(err, lazy.ErrValueNotCached) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := length.LoadContext(context.Background(), nil); !errors.Is(err, lazy.ErrValueNotCached) {
+		t.Fatalf("LoadContext err = %v", err)
+	}
+	if length.IsLoaded() || double.IsLoaded() {
+		t.Fatal("derived value cached a result before src loaded")
+	}
+
+	Must(src.Load(func() (string, error) { return "hello", nil }))
+	if n := Must(double.Load(nil)); n != 10 {
+		t.Fatalf("double = %d", n)
+	}
+}
+
+func TestLoadNilUnloaded(t *testing.T) {
+	var v lazy.Value[int]
+	if _, err := v.Load(nil); !errors.Is(err, lazy.ErrValueNotCached) {
+		t.Fatalf("err = %v", err)
+	}
+	if v.IsLoaded() {
+		t.Fatal("Load(nil) stored a result")
+	}
+}
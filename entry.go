@@ -0,0 +1,36 @@
+package lazy
+
+import (
+	"time"
+)
+
+// Entry is a snapshot of a cached value along with its metadata.
+type Entry[V any] struct {
+	// Value is the value returned for the key.
+	Value V
+	// CreatedAt is when the value was loaded, or the zero time if it isn't loaded.
+	CreatedAt time.Time
+	// Uses is how many times the value has been accessed, including this lookup.
+	Uses int64
+	// Loaded reports whether the key holds a loaded value.
+	Loaded bool
+	// Err is the error cached with the value, if its fetch failed.
+	Err error
+}
+
+// GetEntry is like Get, but returns the value together with its metadata.
+func (lm *LazyMap[K, V]) GetEntry(key K, fetch func(K) (V, error), opts ...Option[K, V]) (Entry[V], error) {
+	var lv *Value[V]
+	combinedOpts := make([]Option[K, V], 0, len(lm.opts)+len(opts)+1)
+	combinedOpts = append(combinedOpts, lm.opts...)
+	combinedOpts = append(combinedOpts, opts...)
+	combinedOpts = append(combinedOpts, WithValueDest[K, V](&lv))
+	v, err := Map(&lm.m, &lm.mu, key, fetch, combinedOpts...)
+	e := Entry[V]{Value: v}
+	if lv != nil {
+		_, e.Loaded, e.Err = lv.Value()
+		e.CreatedAt = lv.CreatedAt()
+		e.Uses = lv.Uses()
+	}
+	return e, err
+}
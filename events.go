@@ -0,0 +1,137 @@
+package lazy
+
+import "sync"
+
+// EventType identifies what happened to a key in an Event.
+type EventType int
+
+const (
+	// EventSet is published when a value is stored directly, by Set, Put, SetMany and so on.
+	EventSet EventType = iota
+	// EventLoaded is published when a fetch succeeds.
+	EventLoaded
+	// EventEvicted is published when an entry is evicted to keep the map within MaxSize.
+	EventEvicted
+	// EventExpired is published when an expired entry is removed.
+	EventExpired
+	// EventRemoved is published when an entry is removed explicitly, e.g. by Delete or Clear.
+	EventRemoved
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventLoaded:
+		return "loaded"
+	case EventEvicted:
+		return "evicted"
+	case EventExpired:
+		return "expired"
+	case EventRemoved:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// removalEvents maps the removal reasons that are published to their event types. Swapped
+// entries aren't published: the value replacing them is.
+var removalEvents = map[RemovalReason]EventType{
+	RemovalEvicted:        EventEvicted,
+	RemovalExpired:        EventExpired,
+	RemovalCleared:        EventRemoved,
+	RemovalReleased:       EventExpired,
+	RemovalInvalidatedAll: EventExpired,
+}
+
+// Event describes a change to a LazyMap entry, as delivered to subscribers.
+type Event[K comparable, V any] struct {
+	Type EventType
+	Key  K
+	// Value is the value stored, loaded or removed. It is the zero value for removed entries
+	// that never loaded successfully.
+	Value V
+}
+
+// eventBufferSize is how many events a subscriber can fall behind by before events are dropped.
+const eventBufferSize = 64
+
+// eventHub fans events out to a LazyMap's subscribers.
+type eventHub[K comparable, V any] struct {
+	mu   sync.Mutex
+	subs map[chan Event[K, V]]struct{}
+}
+
+func (h *eventHub[K, V]) subscribe() (<-chan Event[K, V], func()) {
+	ch := make(chan Event[K, V], eventBufferSize)
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[chan Event[K, V]]struct{})
+	}
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// publish delivers e to every subscriber with room for it, dropping it for the rest.
+func (h *eventHub[K, V]) publish(e Event[K, V]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// withEvents returns an Option that publishes cache events to h. NewLazyMap adds it, like
+// withStats, so that every call on the map publishes to the same subscribers.
+func withEvents[K comparable, V any](h *eventHub[K, V]) Option[K, V] {
+	return func(a *args[K, V]) { a.events = h }
+}
+
+// publish sends an event to the map's subscribers, if any.
+func (a *args[K, V]) publish(t EventType, key K, v V) {
+	if a.events != nil {
+		a.events.publish(Event[K, V]{Type: t, Key: key, Value: v})
+	}
+}
+
+// publishing wraps load so that a successful load publishes EventLoaded.
+func (a *args[K, V]) publishing(key K, load func() (V, error)) func() (V, error) {
+	if a.events == nil {
+		return load
+	}
+	return func() (V, error) {
+		v, err := load()
+		if err == nil {
+			a.publish(EventLoaded, key, v)
+		}
+		return v, err
+	}
+}
+
+// Subscribe returns a channel that receives an Event for each change to the map, and a function
+// that unsubscribes and closes the channel. Events are sent without blocking the map: a
+// subscriber that falls more than 64 events behind misses events until it catches up. Events
+// for a key are sent in order, but events for a fetch may arrive just before its value is
+// visible to other callers. A LazyMap not created with NewLazyMap publishes no events, and
+// its channel is closed straight away.
+func (lm *LazyMap[K, V]) Subscribe() (<-chan Event[K, V], func()) {
+	h := lm.config().events
+	if h == nil {
+		ch := make(chan Event[K, V])
+		close(ch)
+		return ch, func() {}
+	}
+	return h.subscribe()
+}
@@ -0,0 +1,60 @@
+package lazy_test
+
+import (
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestLazyMapSubscribe(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](lazy.MaxSize[string, int](2))
+	events, unsubscribe := lm.Subscribe()
+	defer unsubscribe()
+
+	next := func() lazy.Event[string, int] {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for an event")
+			return lazy.Event[string, int]{}
+		}
+	}
+	expect := func(typ lazy.EventType, key string, v int) {
+		t.Helper()
+		want := lazy.Event[string, int]{Type: typ, Key: key, Value: v}
+		if got := next(); got != want {
+			t.Fatalf("Expected %+v, got %+v", want, got)
+		}
+	}
+
+	Must(lm.Get("a", func(string) (int, error) { return 1, nil }))
+	expect(lazy.EventLoaded, "a", 1)
+	// A cache hit publishes nothing.
+	Must(lm.Get("a", func(string) (int, error) { return 9, nil }))
+
+	lm.Set("b", 2)
+	expect(lazy.EventSet, "b", 2)
+	lm.Put("b", 3)
+	expect(lazy.EventSet, "b", 3)
+
+	lm.Remove("a")
+	expect(lazy.EventRemoved, "a", 1)
+
+	lm.Set("c", 4)
+	lm.Set("d", 5)
+	expect(lazy.EventSet, "c", 4)
+	// The map is full, so storing d evicts one of b or c before d is set.
+	if e := next(); e.Type != lazy.EventEvicted {
+		t.Fatalf("Expected an eviction, got %+v", e)
+	}
+	expect(lazy.EventSet, "d", 5)
+
+	unsubscribe()
+	if _, ok := <-events; ok {
+		t.Fatal("Expected the channel to be closed after unsubscribing")
+	}
+	lm.Set("e", 6) // Publishing after unsubscribing must not panic.
+}
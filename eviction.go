@@ -0,0 +1,214 @@
+package lazy
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// evict removes a single entry from m using policy to make room for id, returning the removed
+// key and value. If policy is nil, an arbitrary entry is removed. Pinned entries are never
+// removed; if every entry is pinned, nothing is.
+func evict[K comparable, V any](m map[K]*Value[V], policy EvictionPolicy[K, V], id K) (K, *Value[V], bool) {
+	if gp, ok := policy.(*groupedEvictionPolicy[K, V]); ok {
+		policy = gp.forInsert(id)
+	}
+	var victim K
+	var found bool
+	if policy != nil {
+		victim, found = selectVictim(m, policy)
+	} else {
+		victim, found = anyUnpinned(m)
+	}
+	if !found {
+		var zero K
+		return zero, nil, false
+	}
+	lv := m[victim]
+	delete(m, victim)
+	return victim, lv, true
+}
+
+// selectVictim asks policy for an unpinned key of m to evict. Policies forget the key they
+// select, so pinned keys passed over along the way are registered with the policy again. A key
+// that is no longer in m, which a policy can return if an Access raced its removal, is skipped.
+func selectVictim[K comparable, V any](m map[K]*Value[V], policy EvictionPolicy[K, V]) (K, bool) {
+	var pinned, gone []K
+	defer func() {
+		for _, k := range pinned {
+			insert(policy, k)
+			policy.Access(k)
+		}
+	}()
+	for {
+		victim, found := policy.SelectVictim(m)
+		if !found {
+			return victim, false
+		}
+		lv, ok := m[victim]
+		if ok && !lv.has(flagPinned) {
+			return victim, true
+		}
+		skipped := &gone
+		if ok {
+			skipped = &pinned
+		}
+		if slices.Contains(*skipped, victim) {
+			// The policy keeps offering a key it can't have, such as a pinned key it ranks lowest.
+			break
+		}
+		*skipped = append(*skipped, victim)
+	}
+	return selectHidingPinned(m, policy)
+}
+
+// selectHidingPinned asks policy for a victim with the pinned keys taken out of m for the
+// duration, falling back to an arbitrary unpinned key if the policy still picks one that
+// can't be evicted. m is restored before it returns.
+func selectHidingPinned[K comparable, V any](m map[K]*Value[V], policy EvictionPolicy[K, V]) (K, bool) {
+	hidden := make(map[K]*Value[V])
+	for k, lv := range m {
+		if lv.has(flagPinned) {
+			hidden[k] = lv
+		}
+	}
+	for k := range hidden {
+		delete(m, k)
+	}
+	victim, found := policy.SelectVictim(m)
+	maps.Copy(m, hidden)
+	if !found {
+		return victim, false
+	}
+	if lv, ok := m[victim]; ok && !lv.has(flagPinned) {
+		return victim, true
+	}
+	return anyUnpinned(m)
+}
+
+// anyUnpinned returns an arbitrary key of m that isn't pinned.
+func anyUnpinned[K comparable, V any](m map[K]*Value[V]) (K, bool) {
+	for k, lv := range m {
+		if !lv.has(flagPinned) {
+			return k, true
+		}
+	}
+	var zero K
+	return zero, false
+}
+
+// asyncEvictionSlack is how far past maxSize an asynchronously evicted map may grow
+// before inserts fall back to evicting synchronously.
+func asyncEvictionSlack(maxSize int) int {
+	if slack := maxSize / 10; slack > 1 {
+		return slack
+	}
+	return 1
+}
+
+// asyncEvictor trims a map back down to MaxSize from a background goroutine.
+type asyncEvictor[K comparable, V any] struct {
+	pending atomic.Bool
+}
+
+// schedule starts a trim of m down to MaxSize, to make room for id, unless one is already pending.
+// It must be called with mu held for writing.
+func (ae *asyncEvictor[K, V]) schedule(m *map[K]*Value[V], mu *sync.RWMutex, a *args[K, V], id K) {
+	if !ae.pending.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		var removals []removal[K, V]
+		mu.Lock()
+		// The triggering insert happens after schedule returns, so trim to maxSize.
+		for len(*m) > a.maxSize {
+			k, victim, found := evict(*m, a.evictionPolicy, id)
+			if !found {
+				break
+			}
+			removals = append(removals, removal[K, V]{key: k, value: victim, reason: RemovalEvicted})
+		}
+		if len(removals) > 0 {
+			a.republish(*m)
+		}
+		// Clear pending while still holding the lock so an insert that overshoots after
+		// this trim is guaranteed to schedule another one.
+		ae.pending.Store(false)
+		mu.Unlock()
+		a.removed(removals)
+	}()
+}
+
+// WithEvictionPolicyFor returns an Option that partitions keys into groups, each with its own
+// eviction policy. selector returns the policy for a key, and must return the same policy
+// for a key every time; policies are told apart with ==, so use pointers such as those returned
+// by NewLRUEvictionPolicy. Accesses are passed to the key's policy, and when the map is full the
+// victim is chosen by the inserting key's policy from among the keys in the same group, so
+// each group evicts by its own rules. If the group has no entries yet, an arbitrary entry
+// from the map is evicted. Selecting within a group scans the map, so eviction is O(n).
+// It replaces any policy set with WithEvictionPolicy.
+func WithEvictionPolicyFor[K comparable, V any](selector func(K) EvictionPolicy[K, V]) Option[K, V] {
+	return func(a *args[K, V]) { a.evictionPolicy = &groupedEvictionPolicy[K, V]{selector: selector} }
+}
+
+// groupedEvictionPolicy dispatches to a policy chosen per key; see WithEvictionPolicyFor.
+type groupedEvictionPolicy[K comparable, V any] struct {
+	selector func(K) EvictionPolicy[K, V]
+}
+
+func (p *groupedEvictionPolicy[K, V]) Access(key K) {
+	if policy := p.selector(key); policy != nil {
+		policy.Access(key)
+	}
+}
+
+func (p *groupedEvictionPolicy[K, V]) Insert(key K) {
+	if policy := p.selector(key); policy != nil {
+		insert(policy, key)
+	}
+}
+
+func (p *groupedEvictionPolicy[K, V]) Remove(key K) {
+	if policy, ok := p.selector(key).(RemovalAwareEvictionPolicy[K, V]); ok {
+		policy.Remove(key)
+	}
+}
+
+// SelectVictim is used when no inserting key is known, and picks an arbitrary entry.
+func (p *groupedEvictionPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
+	for k := range m {
+		return k, true
+	}
+	var zero K
+	return zero, false
+}
+
+// forInsert returns a policy that selects a victim from id's group.
+func (p *groupedEvictionPolicy[K, V]) forInsert(id K) EvictionPolicy[K, V] {
+	policy := p.selector(id)
+	if policy == nil {
+		return nil
+	}
+	return &groupVictimPolicy[K, V]{EvictionPolicy: policy, group: p}
+}
+
+// groupVictimPolicy restricts a group's policy to choosing among the group's own keys.
+type groupVictimPolicy[K comparable, V any] struct {
+	EvictionPolicy[K, V]
+	group *groupedEvictionPolicy[K, V]
+}
+
+func (p *groupVictimPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
+	policy := p.EvictionPolicy
+	members := make(map[K]*Value[V])
+	for k, lv := range m {
+		if p.group.selector(k) == policy {
+			members[k] = lv
+		}
+	}
+	if len(members) == 0 {
+		return p.group.SelectVictim(m)
+	}
+	return policy.SelectVictim(members)
+}
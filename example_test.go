@@ -162,3 +162,37 @@ func ExampleLazyMap_withContextExpiry() {
 	// Calls: 1
 	// Calls: 2
 }
+
+func ExampleLazyMap_ReadOnly() {
+	cache := lazy.NewLazyMap[string, int]()
+	view := cache.ReadOnly()
+
+	// The producer fills the cache...
+	cache.Set("answer", 42)
+
+	// ...and consumers read through the view, which can't fetch, set or remove.
+	v, _ := view.Get("answer")
+	fmt.Println(v, view.Len())
+
+	// Output:
+	// 42 1
+}
+
+func ExampleKey2() {
+	cache := lazy.NewLazyMap[lazy.Key2[int, string], string]()
+	fetch := func(k lazy.Key2[int, string]) (string, error) {
+		return fmt.Sprintf("user %d can %s", k.K1, k.K2), nil
+	}
+
+	// Concatenating to "1" + "2read" and "12" + "read" would collide; tuples don't.
+	a, _ := cache.Get(lazy.MakeKey2(1, "2read"), fetch)
+	b, _ := cache.Get(lazy.MakeKey2(12, "read"), fetch)
+	fmt.Println(a)
+	fmt.Println(b)
+	fmt.Println(cache.Len())
+
+	// Output:
+	// user 1 can 2read
+	// user 12 can read
+	// 2
+}
@@ -2,6 +2,8 @@ package lazy
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -10,7 +12,52 @@ type Expiry[V any] interface {
 	IsExpired(v *Value[V]) bool
 }
 
+// ExpiryReasoner is an optional interface for Expiry policies that can say why a value expired,
+// for debugging composite policies. Reason is only meaningful when IsExpired reports true. Every
+// policy in this package implements it.
+type ExpiryReasoner[V any] interface {
+	Expiry[V]
+	Reason(v *Value[V]) string
+}
+
+// ExpiryReason returns why e considers v expired: "deadline" for ExpireAt, "age" for
+// ExpireAfter, "idle" for ExpireAfterLastAccess, "uses" for ExpireAfterUses, "reads" for
+// ExpireAfterReads, "context" for ExpireContext, "predicate" for ExpireWhen, "custom" for
+// ExpireCustom and "never" for NeverExpires, which never expires but still names itself when
+// asked. ExpireWhenAny reports the reason of the first of its policies that has expired, and
+// ExpireWhenAll joins its policies' reasons with "+". Policies that don't implement
+// ExpiryReasoner report "expired".
+func ExpiryReason[V any](e Expiry[V], v *Value[V]) string {
+	if r, ok := e.(ExpiryReasoner[V]); ok {
+		return r.Reason(v)
+	}
+	return "expired"
+}
+
+// deadlineExpiry is implemented by time-based policies that can report when a value will expire.
+type deadlineExpiry[V any] interface {
+	// deadline returns the time at which v expires, or false if it has no deadline.
+	deadline(v *Value[V]) (time.Time, bool)
+}
+
+// TTLRemaining returns how long v has left before it expires under e.
+// It returns false if e has no deadline for v, either because it isn't time-based
+// (ExpireAfterUses, ExpireContext, ExpireCustom, ...) or because v hasn't been loaded.
+// Once the deadline has passed the remaining duration is zero.
+func TTLRemaining[V any](v *Value[V], e Expiry[V]) (time.Duration, bool) {
+	de, ok := e.(deadlineExpiry[V])
+	if !ok {
+		return 0, false
+	}
+	d, ok := de.deadline(v)
+	if !ok {
+		return 0, false
+	}
+	return max(d.Sub(v.now()), 0), true
+}
+
 // ExpireAt returns an Expiry policy that expires the value at the given time.
+// It uses neither CreatedAt nor LastAccess: every value expires at t.
 func ExpireAt[V any](t time.Time) Expiry[V] {
 	return &expireAt[V]{t: t}
 }
@@ -20,10 +67,20 @@ type expireAt[V any] struct {
 }
 
 func (e *expireAt[V]) IsExpired(v *Value[V]) bool {
-	return time.Now().After(e.t)
+	return v.now().After(e.t)
+}
+
+func (e *expireAt[V]) Reason(v *Value[V]) string { return "deadline" }
+
+func (e *expireAt[V]) deadline(v *Value[V]) (time.Time, bool) {
+	if !v.IsLoaded() {
+		return time.Time{}, false
+	}
+	return e.t, true
 }
 
 // ExpireAfter returns an Expiry policy that expires the value after the given duration.
+// The duration counts from CreatedAt, so accessing the value doesn't extend its life.
 func ExpireAfter[V any](d time.Duration) Expiry[V] {
 	return &expireAfter[V]{d: d}
 }
@@ -37,14 +94,31 @@ func (e *expireAfter[V]) IsExpired(v *Value[V]) bool {
 	if createdAt.IsZero() {
 		return false
 	}
-	return time.Since(createdAt) > e.d
+	return v.now().Sub(createdAt) > e.d
+}
+
+func (e *expireAfter[V]) Reason(v *Value[V]) string { return "age" }
+
+func (e *expireAfter[V]) deadline(v *Value[V]) (time.Time, bool) {
+	createdAt := v.CreatedAt()
+	if createdAt.IsZero() {
+		return time.Time{}, false
+	}
+	return createdAt.Add(e.d), true
 }
 
 // ExpireAfterLastAccess returns an Expiry policy that expires the value after the given duration since last access.
+// The duration counts from LastAccess; CreatedAt still reports when the value was loaded.
 func ExpireAfterLastAccess[V any](d time.Duration) Expiry[V] {
 	return &expireAfterLastAccess[V]{d: d}
 }
 
+// ExpireAfterIdle returns an Expiry policy that expires the value once it has gone unaccessed
+// for d. It is the same policy as ExpireAfterLastAccess.
+func ExpireAfterIdle[V any](d time.Duration) Expiry[V] {
+	return ExpireAfterLastAccess[V](d)
+}
+
 type expireAfterLastAccess[V any] struct {
 	d time.Duration
 }
@@ -54,10 +128,14 @@ func (e *expireAfterLastAccess[V]) IsExpired(v *Value[V]) bool {
 	if lastAccess.IsZero() {
 		return false
 	}
-	return time.Since(lastAccess) > e.d
+	return v.now().Sub(lastAccess) > e.d
 }
 
+func (e *expireAfterLastAccess[V]) Reason(v *Value[V]) string { return "idle" }
+
 // ExpireAfterUses returns an Expiry policy that expires the value after the given number of uses.
+// It counts Uses and ignores both timestamps. The load counts as a use, so ExpireAfterUses(1)
+// refetches on every access; see ExpireAfterReads to count only cached reads.
 func ExpireAfterUses[V any](n int64) Expiry[V] {
 	return &expireAfterUses[V]{n: n}
 }
@@ -70,6 +148,26 @@ func (e *expireAfterUses[V]) IsExpired(v *Value[V]) bool {
 	return v.Uses() >= e.n
 }
 
+func (e *expireAfterUses[V]) Reason(v *Value[V]) string { return "uses" }
+
+// ExpireAfterReads returns an Expiry policy that expires the value after n reads following the
+// load that produced it, so ExpireAfterReads(1) allows exactly one cached read before the value
+// is fetched again. Unlike ExpireAfterUses, the load itself isn't counted; a value set directly,
+// which isn't loaded, counts every access as a read.
+func ExpireAfterReads[V any](n int64) Expiry[V] {
+	return &expireAfterReads[V]{n: n}
+}
+
+type expireAfterReads[V any] struct {
+	n int64
+}
+
+func (e *expireAfterReads[V]) IsExpired(v *Value[V]) bool {
+	return v.reads() >= e.n
+}
+
+func (e *expireAfterReads[V]) Reason(v *Value[V]) string { return "reads" }
+
 // ExpireWhenAll returns an Expiry policy that expires if ALL of the given policies expire.
 func ExpireWhenAll[V any](policies ...Expiry[V]) Expiry[V] {
 	return &expireWhenAll[V]{policies: policies}
@@ -91,6 +189,33 @@ func (e *expireWhenAll[V]) IsExpired(v *Value[V]) bool {
 	return true
 }
 
+func (e *expireWhenAll[V]) Reason(v *Value[V]) string {
+	reasons := make([]string, len(e.policies))
+	for i, p := range e.policies {
+		reasons[i] = ExpiryReason(p, v)
+	}
+	return strings.Join(reasons, "+")
+}
+
+// deadline is the latest deadline of the policies, provided they all have one.
+func (e *expireWhenAll[V]) deadline(v *Value[V]) (time.Time, bool) {
+	var latest time.Time
+	for _, p := range e.policies {
+		de, ok := p.(deadlineExpiry[V])
+		if !ok {
+			return time.Time{}, false
+		}
+		d, ok := de.deadline(v)
+		if !ok {
+			return time.Time{}, false
+		}
+		if d.After(latest) {
+			latest = d
+		}
+	}
+	return latest, !latest.IsZero()
+}
+
 // ExpireAll returns an Expiry policy that expires if ALL of the given policies expire.
 // Deprecated: Use ExpireWhenAll instead.
 func ExpireAll[V any](policies ...Expiry[V]) Expiry[V] {
@@ -115,12 +240,95 @@ func (e *expireWhenAny[V]) IsExpired(v *Value[V]) bool {
 	return false
 }
 
+// Reason is the reason of the first policy that has expired.
+func (e *expireWhenAny[V]) Reason(v *Value[V]) string {
+	for _, p := range e.policies {
+		if p.IsExpired(v) {
+			return ExpiryReason(p, v)
+		}
+	}
+	return "expired"
+}
+
+// deadline is the earliest deadline among the policies that have one.
+func (e *expireWhenAny[V]) deadline(v *Value[V]) (time.Time, bool) {
+	var earliest time.Time
+	found := false
+	for _, p := range e.policies {
+		de, ok := p.(deadlineExpiry[V])
+		if !ok {
+			continue
+		}
+		if d, ok := de.deadline(v); ok && (!found || d.Before(earliest)) {
+			earliest = d
+			found = true
+		}
+	}
+	return earliest, found
+}
+
 // ExpireAny returns an Expiry policy that expires if ANY of the given policies expire.
 // Deprecated: Use ExpireWhenAny instead.
 func ExpireAny[V any](policies ...Expiry[V]) Expiry[V] {
 	return ExpireWhenAny(policies...)
 }
 
+// ExpiryBuilder composes Expiry policies fluently, for example
+//
+//	NewExpiry[V]().After(5 * time.Minute).At(midnight).AnyOf()
+//
+// expires five minutes after loading or at midnight, whichever comes first.
+type ExpiryBuilder[V any] struct {
+	policies []Expiry[V]
+}
+
+// NewExpiry returns an empty ExpiryBuilder.
+func NewExpiry[V any]() *ExpiryBuilder[V] {
+	return &ExpiryBuilder[V]{}
+}
+
+// After adds ExpireAfter(d).
+func (b *ExpiryBuilder[V]) After(d time.Duration) *ExpiryBuilder[V] {
+	return b.With(ExpireAfter[V](d))
+}
+
+// At adds ExpireAt(t).
+func (b *ExpiryBuilder[V]) At(t time.Time) *ExpiryBuilder[V] {
+	return b.With(ExpireAt[V](t))
+}
+
+// AfterIdle adds ExpireAfterIdle(d).
+func (b *ExpiryBuilder[V]) AfterIdle(d time.Duration) *ExpiryBuilder[V] {
+	return b.With(ExpireAfterIdle[V](d))
+}
+
+// AfterUses adds ExpireAfterUses(n).
+func (b *ExpiryBuilder[V]) AfterUses(n int64) *ExpiryBuilder[V] {
+	return b.With(ExpireAfterUses[V](n))
+}
+
+// WhenContextDone adds ExpireContext(ctx).
+func (b *ExpiryBuilder[V]) WhenContextDone(ctx context.Context) *ExpiryBuilder[V] {
+	return b.With(ExpireContext[V](ctx))
+}
+
+// With adds an arbitrary policy.
+func (b *ExpiryBuilder[V]) With(e Expiry[V]) *ExpiryBuilder[V] {
+	b.policies = append(b.policies, e)
+	return b
+}
+
+// AnyOf returns a policy that expires when any of the added policies does, as ExpireWhenAny.
+func (b *ExpiryBuilder[V]) AnyOf() Expiry[V] {
+	return ExpireWhenAny(slices.Clone(b.policies)...)
+}
+
+// AllOf returns a policy that expires when all of the added policies do, as ExpireWhenAll.
+// With no policies added it never expires.
+func (b *ExpiryBuilder[V]) AllOf() Expiry[V] {
+	return ExpireWhenAll(slices.Clone(b.policies)...)
+}
+
 // NeverExpires returns an Expiry policy that never expires.
 func NeverExpires[V any]() Expiry[V] {
 	return &neverExpires[V]{}
@@ -132,6 +340,8 @@ func (e *neverExpires[V]) IsExpired(v *Value[V]) bool {
 	return false
 }
 
+func (e *neverExpires[V]) Reason(v *Value[V]) string { return "never" }
+
 // ExpireCustom returns an Expiry policy that uses a custom function.
 func ExpireCustom[V any](f func(v *Value[V]) bool) Expiry[V] {
 	return &expireCustom[V]{f: f}
@@ -148,6 +358,30 @@ func (e *expireCustom[V]) IsExpired(v *Value[V]) bool {
 	return e.f(v)
 }
 
+func (e *expireCustom[V]) Reason(v *Value[V]) string { return "custom" }
+
+// ExpireWhen returns an Expiry policy that expires the value when pred returns true.
+// pred receives the cached value, when it was loaded and how many times it has been used,
+// read without counting as a use. Unloaded values and values loaded with an error are
+// never passed to pred and don't expire.
+func ExpireWhen[V any](pred func(v V, createdAt time.Time, uses int64) bool) Expiry[V] {
+	return &expireWhen[V]{pred: pred}
+}
+
+type expireWhen[V any] struct {
+	pred func(v V, createdAt time.Time, uses int64) bool
+}
+
+func (e *expireWhen[V]) IsExpired(v *Value[V]) bool {
+	r := v.val.Load()
+	if r == nil || r.err != nil || e.pred == nil {
+		return false
+	}
+	return e.pred(r.value, r.created(), v.Uses())
+}
+
+func (e *expireWhen[V]) Reason(v *Value[V]) string { return "predicate" }
+
 // ExpireContext returns an Expiry policy that expires when the given context is cancelled or times out.
 func ExpireContext[V any](ctx context.Context) Expiry[V] {
 	return &expireContext[V]{ctx: ctx}
@@ -160,3 +394,5 @@ type expireContext[V any] struct {
 func (e *expireContext[V]) IsExpired(v *Value[V]) bool {
 	return e.ctx.Err() != nil
 }
+
+func (e *expireContext[V]) Reason(v *Value[V]) string { return "context" }
@@ -2,7 +2,9 @@ package lazy
 
 import (
 	"context"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -91,6 +93,38 @@ func TestExpireAfterUses(t *testing.T) {
 	}
 }
 
+func TestExpireAfterReads(t *testing.T) {
+	var mu sync.RWMutex
+	m := make(map[string]*Value[int])
+	opts := []Option[string, int]{WithExpiry[string, int](ExpireAfterReads[int](2))}
+	fetchCount := 0
+	fetch := func(string) (int, error) {
+		fetchCount++
+		return fetchCount, nil
+	}
+
+	// The load, then two cache hits, then a refetch.
+	var got []int
+	for i := 0; i < 5; i++ {
+		v, err := Map(&m, &mu, "key", fetch, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	if want := []int{1, 1, 1, 2, 2}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// A value set directly wasn't loaded, so every access is a read.
+	var v Value[int]
+	v.Set(1)
+	v.Peek()
+	if e := ExpireAfterReads[int](1); !e.IsExpired(&v) {
+		t.Fatal("expected set value to expire after one read")
+	}
+}
+
 func TestExpireAt(t *testing.T) {
 	var mu sync.RWMutex
 	m := make(map[string]*Value[int])
@@ -351,6 +385,218 @@ func TestExpireAfterLastAccess(t *testing.T) {
 	}
 }
 
+func TestCreatedAtFixedLastAccessAdvances(t *testing.T) {
+	var v Value[int]
+	_, _ = v.Load(func() (int, error) { return 1, nil })
+	created := v.CreatedAt()
+	prev := v.LastAccess()
+	if created.IsZero() || prev.IsZero() {
+		t.Fatalf("CreatedAt=%v LastAccess=%v", created, prev)
+	}
+	for i := 0; i < 3; i++ {
+		time.Sleep(2 * time.Millisecond)
+		if i%2 == 0 {
+			v.Peek()
+		} else {
+			_, _ = v.Load(nil)
+		}
+		if !v.CreatedAt().Equal(created) {
+			t.Fatalf("CreatedAt changed from %v to %v", created, v.CreatedAt())
+		}
+		if !v.LastAccess().After(prev) {
+			t.Fatalf("LastAccess did not advance past %v", prev)
+		}
+		prev = v.LastAccess()
+	}
+}
+
+func TestExpireAfterIdle(t *testing.T) {
+	lm := NewLazyMap[string, int](WithExpiry[string, int](ExpireAfterIdle[int](30 * time.Millisecond)))
+	calls := 0
+	fetch := func(string) (int, error) { calls++; return calls, nil }
+
+	_, _ = lm.Get("a", fetch)
+	var lv *Value[int]
+	// Keep accessing within the idle window for longer than the window itself.
+	for i := 0; i < 4; i++ {
+		time.Sleep(10 * time.Millisecond)
+		if v, _ := lm.Get("a", fetch, WithValueDest[string, int](&lv)); v != 1 {
+			t.Fatalf("expired while in use, got %d", v)
+		}
+	}
+	if time.Since(lv.CreatedAt()) < 40*time.Millisecond {
+		t.Fatalf("CreatedAt moved: %v", lv.CreatedAt())
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if v, _ := lm.Get("a", fetch); v != 2 {
+		t.Fatalf("expected idle value to expire, got %d", v)
+	}
+}
+
+func TestExpiredValueResetInPlace(t *testing.T) {
+	lru := NewLRUEvictionPolicy[string, int]()
+	lm := NewLazyMap[string, int](
+		WithExpiry[string, int](ExpireAfterUses[int](2)),
+		WithEvictionPolicy[string, int](lru),
+		MaxSize[string, int](10),
+	)
+	calls := 0
+	fetch := func(string) (int, error) { calls++; return calls, nil }
+
+	var first *Value[int]
+	_, _ = lm.Get("a", fetch, WithValueDest[string, int](&first))
+	for i := 0; i < 20; i++ {
+		var lv *Value[int]
+		_, _ = lm.Get("a", fetch, WithValueDest[string, int](&lv))
+		if lv != first {
+			t.Fatalf("iteration %d: expired entry was replaced rather than reset", i)
+		}
+	}
+	if calls < 10 {
+		t.Fatalf("expected repeated expiry, calls=%d", calls)
+	}
+	lru.mu.Lock()
+	n := lru.queue.Len()
+	lru.mu.Unlock()
+	if n != 1 || lm.Len() != 1 {
+		t.Fatalf("LRU nodes=%d map len=%d", n, lm.Len())
+	}
+}
+
+func TestExpiredKeyRefetchedOnce(t *testing.T) {
+	var mu sync.RWMutex
+	m := make(map[string]*Value[time.Time])
+	boundary := time.Now().Add(20 * time.Millisecond)
+	// Values loaded before the boundary expire once it passes; the check is slow so that
+	// callers pile up between seeing the expiry and taking the write lock.
+	expiry := ExpireCustom(func(v *Value[time.Time]) bool {
+		time.Sleep(time.Millisecond)
+		loaded, _, _ := v.Value()
+		return loaded.Before(boundary) && time.Now().After(boundary)
+	})
+	var calls atomic.Int32
+	fetch := func(string) (time.Time, error) {
+		calls.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return time.Now(), nil
+	}
+	opts := []Option[string, time.Time]{WithExpiry[string, time.Time](expiry)}
+	if _, err := Map(&m, &mu, "k", fetch, opts...); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Until(boundary))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Map(&m, &mu, "k", fetch, opts...); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected exactly one refetch, fetch called %d times", n)
+	}
+}
+
+func TestValueInvalidate(t *testing.T) {
+	var v Value[int]
+	_, _ = v.Load(func() (int, error) { return 1, nil })
+	v.Invalidate()
+	if v.IsLoaded() || v.Uses() != 0 || !v.LastAccess().IsZero() {
+		t.Fatalf("loaded=%v uses=%d lastAccess=%v", v.IsLoaded(), v.Uses(), v.LastAccess())
+	}
+	if got, _ := v.Load(func() (int, error) { return 2, nil }); got != 2 {
+		t.Fatalf("reload got %d", got)
+	}
+}
+
+func TestValueResetUses(t *testing.T) {
+	var v Value[int]
+	_, _ = v.Load(func() (int, error) { return 1, nil })
+	v.Peek()
+	v.Peek()
+	if v.Uses() != 3 {
+		t.Fatalf("uses=%d", v.Uses())
+	}
+	expiry := ExpireAfterUses[int](3)
+	if !expiry.IsExpired(&v) {
+		t.Fatal("expected expired before reset")
+	}
+	v.ResetUses()
+	if v.Uses() != 0 || !v.IsLoaded() {
+		t.Fatalf("uses=%d loaded=%v", v.Uses(), v.IsLoaded())
+	}
+	if expiry.IsExpired(&v) {
+		t.Fatal("expected renewed after reset")
+	}
+	if got, _ := v.Load(func() (int, error) { return 2, nil }); got != 1 {
+		t.Fatalf("reset reloaded the value: %d", got)
+	}
+}
+
+func TestLazyMapTouchKeepsIdleEntryAlive(t *testing.T) {
+	lm := NewLazyMap[string, int](WithExpiry[string, int](ExpireAfterIdle[int](30 * time.Millisecond)))
+	calls := 0
+	fetch := func(string) (int, error) { calls++; return calls, nil }
+
+	_, _ = lm.Get("a", fetch)
+	for i := 0; i < 5; i++ {
+		time.Sleep(10 * time.Millisecond)
+		if !lm.Touch("a") {
+			t.Fatalf("Touch %d failed", i)
+		}
+	}
+	if v, _ := lm.Get("a", fetch); v != 1 {
+		t.Fatalf("touched entry expired, got %d", v)
+	}
+	if lm.Touch("missing") {
+		t.Fatal("Touch on a missing key reported true")
+	}
+
+	uses := NewLazyMap[string, int](WithExpiry[string, int](ExpireAfterUses[int](2)))
+	_, _ = uses.Get("a", fetch)
+	uses.Touch("a")
+	if uses.Touch("a") {
+		t.Fatal("Touch on an entry expired by uses reported true")
+	}
+}
+
+type versioned struct {
+	Version int
+	Data    string
+}
+
+func TestExpireWhen(t *testing.T) {
+	current := 1
+	expiry := ExpireWhen(func(v versioned, createdAt time.Time, uses int64) bool {
+		if createdAt.IsZero() || uses < 1 {
+			t.Errorf("createdAt=%v uses=%d", createdAt, uses)
+		}
+		return v.Version < current
+	})
+	lm := NewLazyMap[string, versioned](WithExpiry[string, versioned](expiry))
+	fetch := func(string) (versioned, error) { return versioned{Version: current, Data: "v"}, nil }
+
+	first, _ := lm.Get("a", fetch)
+	if v, _ := lm.Get("a", fetch); v.Version != 1 {
+		t.Fatalf("got %+v", v)
+	}
+	current = 2
+	if v, _ := lm.Get("a", fetch); v.Version != 2 || first.Version != 1 {
+		t.Fatalf("stale version not expired, got %+v", v)
+	}
+
+	var unloaded Value[versioned]
+	if expiry.IsExpired(&unloaded) {
+		t.Fatal("unloaded value reported expired")
+	}
+}
+
 func TestExpireWhenAll(t *testing.T) {
 	var mu sync.RWMutex
 	m := make(map[string]*Value[int])
@@ -435,3 +681,126 @@ func TestExpireWhenAny(t *testing.T) {
 		t.Errorf("expected 2 fetches, got %d", fetchCount)
 	}
 }
+
+func TestTTLRemaining(t *testing.T) {
+	var v Value[int]
+	after := ExpireAfter[int](time.Hour)
+	if _, ok := TTLRemaining(&v, after); ok {
+		t.Fatal("expected no TTL before load")
+	}
+	if _, ok := TTLRemaining(&v, ExpireAt[int](time.Now().Add(time.Hour))); ok {
+		t.Fatal("expected no ExpireAt TTL before load")
+	}
+	v.Set(1)
+
+	first, ok := TTLRemaining(&v, after)
+	if !ok || first <= 0 || first > time.Hour {
+		t.Fatalf("got %v %v", first, ok)
+	}
+	time.Sleep(10 * time.Millisecond)
+	second, ok := TTLRemaining(&v, after)
+	if !ok || second >= first {
+		t.Fatalf("expected remaining to shrink: %v then %v", first, second)
+	}
+
+	if _, ok := TTLRemaining(&v, ExpireAfterUses[int](3)); ok {
+		t.Fatal("expected no TTL for a uses based policy")
+	}
+	if d, ok := TTLRemaining(&v, ExpireAt[int](time.Now().Add(-time.Second))); !ok || d != 0 {
+		t.Fatalf("expected zero TTL for a past deadline, got %v %v", d, ok)
+	}
+	combined := ExpireWhenAny(ExpireAfterUses[int](3), ExpireAfter[int](time.Minute), after)
+	if d, ok := TTLRemaining(&v, combined); !ok || d > time.Minute {
+		t.Fatalf("expected the earliest deadline, got %v %v", d, ok)
+	}
+}
+
+func TestExpiryBuilder(t *testing.T) {
+	past := time.Now().Add(-time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var v Value[int]
+	v.Set(1)
+
+	b := NewExpiry[int]().After(time.Hour).At(past).AfterUses(10).WhenContextDone(ctx)
+	tests := []struct {
+		name    string
+		got     Expiry[int]
+		want    Expiry[int]
+		expired bool
+	}{
+		{"AnyOf", b.AnyOf(), ExpireWhenAny(ExpireAfter[int](time.Hour), ExpireAt[int](past), ExpireAfterUses[int](10), ExpireContext[int](ctx)), true},
+		{"AllOf", b.AllOf(), ExpireWhenAll(ExpireAfter[int](time.Hour), ExpireAt[int](past), ExpireAfterUses[int](10), ExpireContext[int](ctx)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := tt.got.IsExpired(&v), tt.want.IsExpired(&v); got != want || got != tt.expired {
+				t.Fatalf("builder policy expired=%v, equivalent policy expired=%v, want %v", got, want, tt.expired)
+			}
+		})
+	}
+
+	// The deadline of the combined policy is still reported.
+	if d, ok := TTLRemaining(&v, NewExpiry[int]().After(time.Hour).After(time.Minute).AnyOf()); !ok || d > time.Minute {
+		t.Fatalf("expected the earliest deadline, got %v %v", d, ok)
+	}
+}
+
+func TestWithTimeSource(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	lm := NewLazyMap[string, int](
+		WithTimeSource[string, int](clock),
+		WithExpiry[string, int](ExpireAfter[int](time.Minute)),
+	)
+	fetches := 0
+	fetch := func(string) (int, error) {
+		fetches++
+		return fetches, nil
+	}
+
+	var lv *Value[int]
+	if v, err := lm.Get("k", fetch, WithValueDest[string, int](&lv)); err != nil || v != 1 {
+		t.Fatalf("got %v %v", v, err)
+	}
+	if !lv.CreatedAt().Equal(clock.Now()) {
+		t.Fatalf("expected CreatedAt from the fake clock, got %v", lv.CreatedAt())
+	}
+
+	clock.Advance(59 * time.Second)
+	if d, ok := TTLRemaining(lv, ExpireAfter[int](time.Minute)); !ok || d != time.Second {
+		t.Fatalf("expected 1s remaining, got %v %v", d, ok)
+	}
+	if v, _ := lm.Get("k", fetch); v != 1 {
+		t.Fatalf("expected the cached value before the minute is up, got %d", v)
+	}
+
+	clock.Advance(2 * time.Second)
+	if v, _ := lm.Get("k", fetch); v != 2 {
+		t.Fatalf("expected a refetch once the fake clock passed the expiry, got %d", v)
+	}
+}
+
+func TestExpiryReason(t *testing.T) {
+	var v Value[int]
+	v.Set(1)
+	past := time.Now().Add(-time.Second)
+	anyOf := ExpireWhenAny(ExpireAfter[int](time.Hour), ExpireAt[int](past))
+	if got := ExpiryReason(anyOf, &v); got != "deadline" {
+		t.Errorf("ExpireWhenAny reason = %q, want deadline", got)
+	}
+	allOf := NewExpiry[int]().At(past).AfterUses(0).AllOf()
+	if got := ExpiryReason(allOf, &v); got != "deadline+uses" {
+		t.Errorf("ExpireWhenAll reason = %q, want deadline+uses", got)
+	}
+	if got := ExpiryReason(NeverExpires[int](), &v); got != "never" {
+		t.Errorf("NeverExpires reason = %q, want never", got)
+	}
+	if got := ExpiryReason[int](unreasonedExpiry{}, &v); got != "expired" {
+		t.Errorf("reason for a policy without Reason = %q, want expired", got)
+	}
+}
+
+type unreasonedExpiry struct{}
+
+func (unreasonedExpiry) IsExpired(*Value[int]) bool { return true }
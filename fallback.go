@@ -0,0 +1,49 @@
+package lazy
+
+// WithFallback returns an Option that consults a second-level cache before calling fetch.
+// On a miss in the map, fallback is called first; if it reports found, its value is cached
+// and fetch is not called. If it reports not found, fetch is called as usual. An error from
+// fallback is treated like a fetch error. This allows a fast in-process LazyMap (L1) to sit
+// in front of a slower shared store (L2).
+func WithFallback[K comparable, V any](fallback func(K) (V, bool, error)) Option[K, V] {
+	return func(a *args[K, V]) { a.fallback = fallback }
+}
+
+// WithWriteBack returns an Option that calls writeBack with each value successfully returned
+// by fetch, for example to populate the store used by WithFallback. It is not called for
+// values that came from the fallback, or for values set directly.
+func WithWriteBack[K comparable, V any](writeBack func(K, V)) Option[K, V] {
+	return func(a *args[K, V]) { a.writeBack = writeBack }
+}
+
+// loader returns the function that loads id: the fallback if configured, then fetch with
+// any retries, writing the fetched value back if configured. The whole load is timed for Metrics
+// and, with WithLoaderGroup, shared with concurrent loads of id by other maps. A successful load
+// is published to subscribers.
+func (a *args[K, V]) loader(id K, fetch func(K) (V, error)) func() (V, error) {
+	load := a.retrying(a.recovering(func() (V, error) { return fetch(id) }))
+	if a.fallback == nil && a.writeBack == nil {
+		return a.publishing(id, a.timed(id, a.grouped(id, load)))
+	}
+	return a.publishing(id, a.timed(id, a.grouped(id, func() (V, error) {
+		if a.fallback != nil {
+			v, found, err := a.fallback(id)
+			if err != nil || found {
+				return v, err
+			}
+		}
+		v, err := load()
+		if err == nil && a.writeBack != nil {
+			a.writeBack(id, v)
+		}
+		return v, err
+	})))
+}
+
+// grouped wraps load so that it is coalesced through the LoaderGroup, if one is configured.
+func (a *args[K, V]) grouped(id K, load func() (V, error)) func() (V, error) {
+	if a.loaderGroup == nil {
+		return load
+	}
+	return func() (V, error) { return a.loaderGroup.do(id, load) }
+}
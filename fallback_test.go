@@ -0,0 +1,72 @@
+package lazy_test
+
+import (
+	"errors"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestWithFallback(t *testing.T) {
+	l2 := map[string]string{"far": "from-l2"}
+	fetches := 0
+	var written []string
+	lm := lazy.NewLazyMap[string, string](
+		lazy.WithFallback[string, string](func(k string) (string, bool, error) {
+			v, ok := l2[k]
+			return v, ok, nil
+		}),
+		lazy.WithWriteBack[string, string](func(k, v string) {
+			written = append(written, k)
+			l2[k] = v
+		}),
+	)
+	fetch := func(k string) (string, error) {
+		fetches++
+		return "fetched-" + k, nil
+	}
+
+	t.Run("fallback hit", func(t *testing.T) {
+		if v, err := lm.Get("far", fetch); err != nil || v != "from-l2" {
+			t.Fatalf("got %v %v", v, err)
+		}
+		if fetches != 0 || len(written) != 0 {
+			t.Fatalf("fetches=%d written=%v", fetches, written)
+		}
+	})
+
+	t.Run("fallback miss then fetch", func(t *testing.T) {
+		if v, err := lm.Get("near", fetch); err != nil || v != "fetched-near" {
+			t.Fatalf("got %v %v", v, err)
+		}
+		if fetches != 1 {
+			t.Fatalf("fetches=%d", fetches)
+		}
+		if v, err := lm.Get("near", fetch); err != nil || v != "fetched-near" {
+			t.Fatalf("cached got %v %v", v, err)
+		}
+		if fetches != 1 {
+			t.Fatalf("fetches=%d after cache hit", fetches)
+		}
+	})
+
+	t.Run("write back", func(t *testing.T) {
+		if len(written) != 1 || written[0] != "near" || l2["near"] != "fetched-near" {
+			t.Fatalf("written=%v l2=%v", written, l2)
+		}
+	})
+}
+
+func TestWithFallbackError(t *testing.T) {
+	errL2 := errors.New("l2 down")
+	lm := lazy.NewLazyMap[int, int](lazy.WithFallback[int, int](func(int) (int, bool, error) {
+		return 0, false, errL2
+	}))
+	_, err := lm.Get(1, func(k int) (int, error) {
+		t.Fatal("fetch should not be called")
+		return k, nil
+	})
+	if !errors.Is(err, errL2) {
+		t.Fatalf("err=%v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package lazy
+
+import (
+	"context"
+	"time"
+)
+
+// GetFresh is like GetContext, but never returns a value loaded maxAge or more ago: if the
+// cached value is older than that, or holds an error, it is refreshed and the call blocks until
+// the new value has loaded or ctx is done. A value that is fresh enough is returned without
+// fetching. Concurrent GetFresh calls for a stale key share a single refresh. It suits
+// correctness-sensitive reads that can tolerate a little caching but not stale data, unlike
+// WithRefreshAhead, which keeps serving the old value while it reloads. A maxAge of zero or less always fetches.
+func (lm *LazyMap[K, V]) GetFresh(ctx context.Context, key K, fetch func(context.Context, K) (V, error), maxAge time.Duration, opts ...Option[K, V]) (V, error) {
+	combinedOpts := make([]Option[K, V], 0, len(lm.opts)+len(opts)+2)
+	combinedOpts = append(combinedOpts, lm.opts...)
+	combinedOpts = append(combinedOpts, opts...)
+	combinedOpts = append(combinedOpts, Refresh[K, V](), WithCoalesceWindow[K, V](maxAge))
+	return MapContext(ctx, &lm.m, &lm.mu, key, fetch, combinedOpts...)
+}
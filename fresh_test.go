@@ -0,0 +1,50 @@
+package lazy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestGetFresh(t *testing.T) {
+	clock := lazy.NewFakeClock(time.Now())
+	lm := lazy.NewLazyMap[string, int](lazy.WithTimeSource[string, int](clock))
+	fetches := 0
+	fetch := func(ctx context.Context, key string) (int, error) {
+		fetches++
+		return fetches, nil
+	}
+	ctx := context.Background()
+
+	if v := Must(lm.GetFresh(ctx, "k", fetch, time.Minute)); v != 1 {
+		t.Fatalf("Expected the first load 1, got %d", v)
+	}
+	clock.Advance(30 * time.Second)
+	if v := Must(lm.GetFresh(ctx, "k", fetch, time.Minute)); v != 1 || fetches != 1 {
+		t.Fatalf("Expected the fresh value 1 without a fetch, got %d after %d fetches", v, fetches)
+	}
+	clock.Advance(time.Minute)
+	if v := Must(lm.GetFresh(ctx, "k", fetch, time.Minute)); v != 2 || fetches != 2 {
+		t.Fatalf("Expected a synchronous refresh to 2, got %d after %d fetches", v, fetches)
+	}
+	if v := Must(lm.Get("k", nil, lazy.DontFetch[string, int]())); v != 2 {
+		t.Fatalf("Expected the refreshed value to be cached, got %d", v)
+	}
+}
+
+func TestGetFreshContextDone(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	block := make(chan struct{})
+	defer close(block)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := lm.GetFresh(ctx, "k", func(ctx context.Context, key string) (int, error) {
+		<-block
+		return 1, nil
+	}, time.Minute)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expected the refresh wait to end with ctx, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package lazy_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+// FuzzMap runs random sequences of operations against Map from several goroutines and checks
+// invariants of its locking state machine. Run it with -race for the most value:
+//
+//	go test -race -run '^$' -fuzz FuzzMap
+func FuzzMap(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15})
+	f.Add([]byte{0x10, 0x21, 0x32, 0x43, 0x54, 0x65, 0x76, 0x07, 0x18, 0x29, 0x3a, 0x4b})
+	f.Add([]byte{0x00, 0x00, 0x11, 0x11, 0x22, 0x22, 0x33, 0x33, 0x44, 0x44, 0x55, 0x55, 0x66, 0x66})
+	f.Add([]byte{0x70, 0x71, 0x72, 0x73, 0x74, 0x75, 0x76, 0x77, 0x20, 0x21, 0x22, 0x23})
+
+	const (
+		keys       = 8
+		maxSize    = 4
+		goroutines = 4
+	)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		m := make(map[int]*lazy.Value[int64])
+		var mu sync.RWMutex
+		policy := lazy.NewLRUEvictionPolicy[int, int64]()
+		base := []lazy.Option[int, int64]{
+			lazy.MaxSize[int, int64](maxSize),
+			lazy.WithEvictionPolicy[int, int64](policy),
+		}
+		var fetches [keys]atomic.Int64
+		// Values encode their key in the high bits, so a value served for the wrong key shows up.
+		fetch := func(k int) (int64, error) {
+			return int64(k)<<32 | fetches[k].Add(1), nil
+		}
+		check := func(k int, v int64) {
+			if v != 0 && int(v>>32) != k {
+				t.Errorf("key %d got value %#x belonging to key %d", k, v, v>>32)
+			}
+		}
+
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := g; i < len(ops); i += goroutines {
+					k := int(ops[i]) % keys
+					opts := base
+					switch ops[i] >> 4 % 7 {
+					case 1:
+						opts = append(opts[:len(opts):len(opts)], lazy.Refresh[int, int64]())
+					case 2:
+						opts = append(opts[:len(opts):len(opts)], lazy.Set[int, int64](int64(k)<<32))
+					case 3:
+						opts = append(opts[:len(opts):len(opts)], lazy.Clear[int, int64]())
+					case 4:
+						opts = append(opts[:len(opts):len(opts)], lazy.WithExpiry[int, int64](lazy.ExpireAfterUses[int64](2)))
+					case 5:
+						opts = append(opts[:len(opts):len(opts)], lazy.DontFetch[int, int64]())
+					case 6:
+						opts = append(opts[:len(opts):len(opts)], lazy.Refresh[int, int64](), lazy.WithKeepOnRefreshError[int, int64]())
+					}
+					v, err := lazy.Map(&m, &mu, k, fetch, opts...)
+					if err != nil {
+						t.Errorf("op %#x: %v", ops[i], err)
+					}
+					check(k, v)
+					mu.RLock()
+					n := len(m)
+					mu.RUnlock()
+					if n > maxSize {
+						t.Errorf("map holds %d entries, MaxSize %d", n, maxSize)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		// Once the dust settles, a cached key is served without fetching again.
+		for k := 0; k < keys; k++ {
+			first, err := lazy.Map(&m, &mu, k, fetch, base...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(k, first)
+			before := fetches[k].Load()
+			again, _ := lazy.Map(&m, &mu, k, fetch, base...)
+			if again != first {
+				// Only possible if k was evicted in between, which a single goroutine can't cause.
+				t.Errorf("key %d changed from %#x to %#x with no writes", k, first, again)
+			}
+			if after := fetches[k].Load(); after != before {
+				t.Errorf("key %d fetched %d more times on a cache hit", k, after-before)
+			}
+		}
+	})
+}
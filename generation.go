@@ -0,0 +1,39 @@
+package lazy
+
+import "sync/atomic"
+
+// withGeneration returns an Option that makes entries created before the counter last moved
+// count as expired. NewLazyMap adds it, like withStats, so every call shares the map's counter.
+func withGeneration[K comparable, V any](g *atomic.Uint32) Option[K, V] {
+	return func(a *args[K, V]) { a.generation = g }
+}
+
+// currentGeneration returns the map's generation, or zero if it has none.
+func (a *args[K, V]) currentGeneration() uint32 {
+	if a.generation == nil {
+		return 0
+	}
+	return a.generation.Load()
+}
+
+// stale reports whether lv was created, or last reloaded, before the current generation.
+// Generations only move forward, so any other generation is an earlier one, even once the
+// counter wraps.
+func (a *args[K, V]) stale(lv *Value[V]) bool {
+	return a.generation != nil && lv.generation.Load() != a.generation.Load()
+}
+
+// stamp marks lv as belonging to the current generation, for entries reloaded in place.
+func (a *args[K, V]) stamp(lv *Value[V]) {
+	lv.generation.Store(a.currentGeneration())
+}
+
+// Bump invalidates every entry in the map at once, without visiting them: entries loaded
+// before the call are treated as expired, so each is fetched again on its next access and
+// removed by SweepExpired. Loads in progress during the call count as before it. The map must
+// have been created with NewLazyMap; on a zero LazyMap Bump does nothing.
+func (lm *LazyMap[K, V]) Bump() {
+	if g := lm.config().generation; g != nil {
+		g.Add(1)
+	}
+}
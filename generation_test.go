@@ -0,0 +1,48 @@
+package lazy_test
+
+import (
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestLazyMapBump(t *testing.T) {
+	fetches := map[int]int{}
+	fetch := func(k int) (int, error) {
+		fetches[k]++
+		return k * 10, nil
+	}
+	bumped := lazy.NewLazyMap[int, int]()
+	other := lazy.NewLazyMap[int, int]()
+	for k := 0; k < 5; k++ {
+		Must(bumped.Get(k, fetch))
+		Must(other.Get(k+100, fetch))
+	}
+
+	bumped.Bump()
+	for k := 0; k < 5; k++ {
+		if v := Must(bumped.Get(k, fetch)); v != k*10 {
+			t.Fatalf("Expected %d, got %d", k*10, v)
+		}
+		Must(other.Get(k+100, fetch))
+	}
+	for k := 0; k < 5; k++ {
+		if fetches[k] != 2 {
+			t.Fatalf("Expected key %d to reload after Bump, got %d fetches", k, fetches[k])
+		}
+		if fetches[k+100] != 1 {
+			t.Fatalf("Expected key %d of the other map to stay cached, got %d fetches", k+100, fetches[k+100])
+		}
+	}
+
+	// Reloaded entries belong to the new generation and stay cached.
+	Must(bumped.Get(0, fetch))
+	if fetches[0] != 2 {
+		t.Fatalf("Expected the reloaded entry to be cached, got %d fetches", fetches[0])
+	}
+
+	bumped.Bump()
+	if n := bumped.SweepExpired(); n != 5 {
+		t.Fatalf("Expected SweepExpired to remove all 5 entries, removed %d", n)
+	}
+}
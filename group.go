@@ -0,0 +1,84 @@
+package lazy
+
+import (
+	"errors"
+	"runtime/debug"
+	"sync"
+)
+
+// errLoadExited is given to callers waiting on a LoaderGroup load whose fetch called
+// runtime.Goexit instead of returning.
+var errLoadExited = errors.New("lazy: shared load exited without returning")
+
+// LoaderGroup coalesces loads of the same key across several maps. Maps created with
+// WithLoaderGroup for the same group share a single in-flight load per key: if a key is being
+// loaded for one map when another map misses on it, the second map waits for and caches the
+// same result instead of fetching again. Loads that don't overlap are not coalesced.
+//
+// The value is shared, not copied, so if V is a pointer, slice or map every map ends up
+// holding the same underlying data, and a mutation through one map is visible through the
+// others. The zero LoaderGroup is ready to use.
+type LoaderGroup[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*groupCall[V]
+}
+
+// groupCall is a load in progress within a LoaderGroup.
+type groupCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// NewLoaderGroup creates a LoaderGroup.
+func NewLoaderGroup[K comparable, V any]() *LoaderGroup[K, V] {
+	return &LoaderGroup[K, V]{}
+}
+
+// do calls fn for id unless a call for id is already in flight, in which case it waits for
+// that call and returns its result. If fn panics, the panic carries on in the caller that ran
+// it, and the callers waiting on it get a *PanicError instead of a result.
+func (g *LoaderGroup[K, V]) do(id K, fn func() (V, error)) (V, error) {
+	g.mu.Lock()
+	if c, ok := g.calls[id]; ok {
+		g.mu.Unlock()
+		<-c.done
+		return c.value, c.err
+	}
+	if g.calls == nil {
+		g.calls = make(map[K]*groupCall[V])
+	}
+	c := &groupCall[V]{done: make(chan struct{})}
+	g.calls[id] = c
+	g.mu.Unlock()
+
+	returned := false
+	defer func() {
+		var repanic any
+		if !returned {
+			// Don't let waiters mistake the zero value for a successful load.
+			if r := recover(); r != nil {
+				c.err = &PanicError{Value: r, Stack: debug.Stack()}
+				repanic = r
+			} else {
+				c.err = errLoadExited
+			}
+		}
+		g.mu.Lock()
+		delete(g.calls, id)
+		g.mu.Unlock()
+		close(c.done)
+		if repanic != nil {
+			panic(repanic)
+		}
+	}()
+	c.value, c.err = fn()
+	returned = true
+	return c.value, c.err
+}
+
+// WithLoaderGroup returns an Option that coalesces loads through g, so that concurrent misses
+// on the same key in different maps using g call fetch only once. See LoaderGroup.
+func WithLoaderGroup[K comparable, V any](g *LoaderGroup[K, V]) Option[K, V] {
+	return func(a *args[K, V]) { a.loaderGroup = g }
+}
@@ -0,0 +1,95 @@
+package lazy_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestWithLoaderGroup(t *testing.T) {
+	group := lazy.NewLoaderGroup[string, int]()
+	a := lazy.NewLazyMap[string, int](lazy.WithLoaderGroup[string, int](group))
+	b := lazy.NewLazyMap[string, int](lazy.WithLoaderGroup[string, int](group))
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func(k string) (int, error) {
+		fetches.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]int, 6)
+	for i := range results {
+		lm := a
+		if i%2 == 1 {
+			lm = b
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = Must(lm.Get("shared", fetch))
+		}()
+	}
+	// Give every caller time to join the in-flight load before it completes.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("fetches = %d, want 1", n)
+	}
+	for i, v := range results {
+		if v != 42 {
+			t.Fatalf("results[%d] = %d", i, v)
+		}
+	}
+	if v, ok := b.GetIfPresent("shared"); !ok || v != 42 {
+		t.Fatalf("map b not populated: %v %v", v, ok)
+	}
+
+	// Once the shared load has finished, a later miss fetches again.
+	Must(a.Get("shared", fetch, lazy.Refresh[string, int]()))
+	if n := fetches.Load(); n != 2 {
+		t.Fatalf("fetches after refresh = %d, want 2", n)
+	}
+}
+
+func TestLoaderGroupPanic(t *testing.T) {
+	group := lazy.NewLoaderGroup[string, int]()
+	a := lazy.NewLazyMap[string, int](lazy.WithLoaderGroup[string, int](group))
+	b := lazy.NewLazyMap[string, int](lazy.WithLoaderGroup[string, int](group))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v, want the fetch's panic", r)
+			}
+		}()
+		_, _ = a.Get("k", func(string) (int, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+
+	done := make(chan error)
+	go func() {
+		_, err := b.Get("k", func(string) (int, error) { return 1, nil })
+		done <- err
+	}()
+	// Give the second map time to join the in-flight load before it panics.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	if err := <-done; !errors.Is(err, lazy.ErrFetchPanic) {
+		t.Fatalf("waiter got %v, want ErrFetchPanic", err)
+	}
+}
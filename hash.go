@@ -0,0 +1,143 @@
+package lazy
+
+import (
+	"hash/fnv"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Hasher computes a 64-bit hash for a key.
+// It is used by partitioned backends such as ShardedLazyMap to pick a shard.
+type Hasher[K comparable] func(K) uint64
+
+// WithHasher returns an Option that specifies the hash function used to partition keys.
+// If not set, a default hasher is used which handles integers and strings without
+// reflection and falls back to reflection for other key kinds.
+func WithHasher[K comparable, V any](h Hasher[K]) Option[K, V] {
+	return func(a *args[K, V]) { a.hasher = h }
+}
+
+// DefaultHasher returns the hasher used when WithHasher is not provided.
+func DefaultHasher[K comparable]() Hasher[K] {
+	return defaultHash[K]
+}
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// mix64 is the splitmix64 finalizer, used to spread integer keys across shards.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func hashString(s string) uint64 {
+	h := uint64(fnvOffset64)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime64
+	}
+	return h
+}
+
+func defaultHash[K comparable](key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return hashString(k)
+	case int:
+		return mix64(uint64(k))
+	case int8:
+		return mix64(uint64(k))
+	case int16:
+		return mix64(uint64(k))
+	case int32:
+		return mix64(uint64(k))
+	case int64:
+		return mix64(uint64(k))
+	case uint:
+		return mix64(uint64(k))
+	case uint8:
+		return mix64(uint64(k))
+	case uint16:
+		return mix64(uint64(k))
+	case uint32:
+		return mix64(uint64(k))
+	case uint64:
+		return mix64(k)
+	case uintptr:
+		return mix64(uint64(k))
+	}
+	h := fnv.New64a()
+	hashReflect(h, reflect.ValueOf(&key).Elem())
+	return h.Sum64()
+}
+
+func writeUint64(w io.Writer, x uint64) {
+	var b [8]byte
+	for i := range b {
+		b[i] = byte(x >> (8 * i))
+	}
+	_, _ = w.Write(b[:])
+}
+
+// hashReflect feeds the contents of v into w. Values that compare equal produce the same bytes.
+func hashReflect(w io.Writer, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint64(w, 1)
+		} else {
+			writeUint64(w, 0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint64(w, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint64(w, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f == 0 {
+			// +0 and -0 compare equal.
+			f = 0
+		}
+		writeUint64(w, math.Float64bits(f))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		re, im := real(c), imag(c)
+		if re == 0 {
+			re = 0
+		}
+		if im == 0 {
+			im = 0
+		}
+		writeUint64(w, math.Float64bits(re))
+		writeUint64(w, math.Float64bits(im))
+	case reflect.String:
+		_, _ = w.Write([]byte(v.String()))
+		writeUint64(w, uint64(v.Len()))
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		writeUint64(w, uint64(v.Pointer()))
+	case reflect.Interface:
+		if v.IsNil() {
+			writeUint64(w, 0)
+			return
+		}
+		e := v.Elem()
+		_, _ = w.Write([]byte(e.Type().String()))
+		hashReflect(w, e)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashReflect(w, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			hashReflect(w, v.Field(i))
+		}
+	}
+}
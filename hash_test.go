@@ -0,0 +1,77 @@
+package lazy_test
+
+import (
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestDefaultHasherIntKeys(t *testing.T) {
+	h := lazy.DefaultHasher[int]()
+	if h(42) != h(42) {
+		t.Fatal("hash not deterministic")
+	}
+	if h(1) == h(2) {
+		t.Fatal("expected distinct hashes for 1 and 2")
+	}
+
+	sm := lazy.NewShardedLazyMap[int, int](4)
+	calls := 0
+	fetch := func(id int) (int, error) { calls++; return id * 2, nil }
+	for i := 0; i < 100; i++ {
+		if v, err := sm.Get(i, fetch); err != nil || v != i*2 {
+			t.Fatalf("Get(%d) got %v %v", i, v, err)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		if v, err := sm.Get(i, fetch); err != nil || v != i*2 {
+			t.Fatalf("cached Get(%d) got %v %v", i, v, err)
+		}
+	}
+	if calls != 100 {
+		t.Fatalf("calls=%d", calls)
+	}
+	if sm.Len() != 100 {
+		t.Fatalf("Len=%d", sm.Len())
+	}
+}
+
+type tenantKey struct {
+	Tenant string
+	ID     int
+}
+
+func TestDefaultHasherStructKeys(t *testing.T) {
+	h := lazy.DefaultHasher[tenantKey]()
+	if h(tenantKey{"a", 1}) != h(tenantKey{"a", 1}) {
+		t.Fatal("equal keys hashed differently")
+	}
+	if h(tenantKey{"a", 1}) == h(tenantKey{"a", 2}) {
+		t.Fatal("expected distinct hashes")
+	}
+}
+
+func TestShardedLazyMapCustomHasher(t *testing.T) {
+	hashed := 0
+	hasher := func(k tenantKey) uint64 {
+		hashed++
+		return uint64(k.ID)
+	}
+	sm := lazy.NewShardedLazyMap[tenantKey, string](3, lazy.WithHasher[tenantKey, string](hasher))
+	fetch := func(k tenantKey) (string, error) { return k.Tenant, nil }
+
+	if v, err := sm.Get(tenantKey{"x", 1}, fetch); err != nil || v != "x" {
+		t.Fatalf("got %v %v", v, err)
+	}
+	sm.Set(tenantKey{"y", 2}, "set")
+	if v, err := sm.Get(tenantKey{"y", 2}, nil, lazy.DontFetch[tenantKey, string]()); err != nil || v != "set" {
+		t.Fatalf("got %v %v", v, err)
+	}
+	sm.Remove(tenantKey{"x", 1})
+	if sm.Len() != 1 {
+		t.Fatalf("Len=%d", sm.Len())
+	}
+	if hashed != 4 {
+		t.Fatalf("hasher called %d times", hashed)
+	}
+}
@@ -0,0 +1,69 @@
+package lazy
+
+import (
+	"sync"
+	"time"
+)
+
+// StartJanitor starts a goroutine that removes expired entries every interval, so entries that
+// are never accessed again don't linger until their next lookup. Removals are reported like any
+// other expiry (WithRemovalLog, Metrics.OnExpire), and the eviction policy's Remove hook is
+// called for each removed key. The returned function stops the janitor, waiting for a sweep in
+// progress to finish; it is safe to call more than once. With a non-positive interval, nothing is
+// started and stop does nothing. See SweepExpired to sweep on your own schedule instead.
+func (lm *LazyMap[K, V]) StartJanitor(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				lm.SweepExpired()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+}
+
+// SweepExpired removes every loaded entry that the map's Expiry reports as expired and returns
+// how many were removed: a single pass of the janitor, for callers that would rather choose
+// when to clean up than run a background goroutine. Removals are reported as with
+// StartJanitor. Entries invalidated by Bump, loaded by GetContext under a context that has
+// since ended, or holding an error WithNegativeCache no longer serves count as expired, with or
+// without a configured Expiry.
+func (lm *LazyMap[K, V]) SweepExpired() int {
+	a := lm.config()
+	var removals []removal[K, V]
+	lm.mu.Lock()
+	for k, lv := range lm.m {
+		if lv.IsLoaded() && a.isExpired(lv) {
+			delete(lm.m, k)
+			why := a.expiryReason(lv)
+			removals = append(removals, removal[K, V]{key: k, value: lv, reason: expiredBy(why), why: why})
+		}
+	}
+	if len(removals) > 0 {
+		a.republish(lm.m)
+	}
+	lm.mu.Unlock()
+	a.removed(removals)
+	// Unlike an expiry found on lookup, which reloads the entry in place, these keys are gone.
+	if p, ok := a.evictionPolicy.(RemovalAwareEvictionPolicy[K, V]); ok {
+		for _, r := range removals {
+			p.Remove(r.key)
+		}
+	}
+	return len(removals)
+}
@@ -0,0 +1,98 @@
+package lazy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestLazyMapStartJanitor(t *testing.T) {
+	policy := &removeRecordingPolicy{LRUEvictionPolicy: lazy.NewLRUEvictionPolicy[int, int]()}
+	lm := lazy.NewLazyMap[int, int](
+		lazy.WithExpiry[int, int](lazy.ExpireAfter[int](20*time.Millisecond)),
+		lazy.WithEvictionPolicy[int, int](policy),
+		lazy.WithRemovalLog[int, int](10),
+	)
+	stop := lm.StartJanitor(10 * time.Millisecond)
+	defer stop()
+
+	_, _ = lm.Get(1, func(int) (int, error) { return 1, nil })
+	deadline := time.Now().Add(time.Second)
+	for lm.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("janitor didn't remove the expired entry")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// Stop waits for the sweep to finish reporting the removal.
+	stop()
+
+	rs := lm.RecentRemovals(-1)
+	if len(rs) != 1 || rs[0].Key != 1 || rs[0].Reason != lazy.RemovalExpired {
+		t.Fatalf("removals=%v", rs)
+	}
+	if len(policy.removed) != 1 || policy.removed[0] != 1 {
+		t.Fatalf("policy Remove calls=%v", policy.removed)
+	}
+}
+
+func TestLazyMapStartJanitorWithoutExpiry(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	stop := lm.StartJanitor(time.Millisecond)
+	stop()
+	stop()
+}
+
+func TestLazyMapSweepExpired(t *testing.T) {
+	policy := &removeRecordingPolicy{LRUEvictionPolicy: lazy.NewLRUEvictionPolicy[int, int]()}
+	lm := lazy.NewLazyMap[int, int](
+		lazy.WithExpiry[int, int](lazy.ExpireAfter[int](30*time.Millisecond)),
+		lazy.WithEvictionPolicy[int, int](policy),
+	)
+	fetch := func(k int) (int, error) { return k, nil }
+	for k := 0; k < 3; k++ {
+		Must(lm.Get(k, fetch))
+	}
+	time.Sleep(40 * time.Millisecond)
+	Must(lm.Get(10, fetch))
+	Must(lm.Get(11, fetch))
+
+	if n := lm.SweepExpired(); n != 3 {
+		t.Fatalf("swept %d, want 3", n)
+	}
+	if lm.Len() != 2 || len(policy.removed) != 3 {
+		t.Fatalf("len=%d policy Remove calls=%v", lm.Len(), policy.removed)
+	}
+	if n := lm.SweepExpired(); n != 0 {
+		t.Fatalf("second sweep removed %d", n)
+	}
+	if n := lazy.NewLazyMap[int, int]().SweepExpired(); n != 0 {
+		t.Fatalf("sweep without expiry removed %d", n)
+	}
+}
+
+func TestLazyMapSweepWithoutExpiry(t *testing.T) {
+	clock := lazy.NewFakeClock(time.Now())
+	lm := lazy.NewLazyMap[string, int](
+		lazy.WithTimeSource[string, int](clock),
+		lazy.WithNegativeCache[string, int](time.Minute),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	Must(lm.GetContext(ctx, "scoped", func(context.Context, string) (int, error) { return 1, nil }))
+	_, _ = lm.Get("missing", func(string) (int, error) { return 0, lazy.ErrNotFound })
+	Must(lm.Get("kept", func(string) (int, error) { return 2, nil }))
+
+	if n := lm.SweepExpired(); n != 0 {
+		t.Fatalf("swept %d before anything expired", n)
+	}
+	cancel()
+	clock.Advance(2 * time.Minute)
+	if n := lm.SweepExpired(); n != 2 {
+		t.Fatalf("swept %d, want the ended scope and the old not-found result", n)
+	}
+	if keys := lm.Keys(); len(keys) != 1 || keys[0] != "kept" {
+		t.Fatalf("keys=%v", keys)
+	}
+}
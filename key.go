@@ -0,0 +1,25 @@
+package lazy
+
+// Key2 is a comparable two-part key, for maps keyed on a tuple such as (userID, resourceID)
+// without resorting to string concatenation.
+type Key2[A, B comparable] struct {
+	K1 A
+	K2 B
+}
+
+// MakeKey2 returns the Key2 for a and b.
+func MakeKey2[A, B comparable](a A, b B) Key2[A, B] {
+	return Key2[A, B]{K1: a, K2: b}
+}
+
+// Key3 is a comparable three-part key.
+type Key3[A, B, C comparable] struct {
+	K1 A
+	K2 B
+	K3 C
+}
+
+// MakeKey3 returns the Key3 for a, b and c.
+func MakeKey3[A, B, C comparable](a A, b B, c C) Key3[A, B, C] {
+	return Key3[A, B, C]{K1: a, K2: b, K3: c}
+}
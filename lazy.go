@@ -1,62 +1,469 @@
 package lazy
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
 // result holds the value and error for a lazy Value.
+// A result is immutable once stored: readers take the pointer from the Value and may
+// keep using it for as long as they like, so a replaced result can never be known to be
+// unreferenced. Recycling results through a sync.Pool would therefore need reference counting
+// or epoch-based reclamation on the read path, costing more than the allocation it saves.
+// Instead, WithEqual avoids storing a new result when a refresh yields an unchanged value.
 type result[T any] struct {
-	value     T
-	err       error
-	createdAt time.Time
+	value T
+	err   error
+	// createdAt is when the result was created, in nanoseconds since epoch; see created.
+	createdAt int64
+}
+
+// epoch is the origin result creation times are measured from. Holding them as an offset from a
+// time with a monotonic clock reading keeps results small without losing that reading, so
+// expiry isn't thrown by changes to the wall clock.
+var epoch = time.Now()
+
+// created returns the time the result was created.
+func (r *result[T]) created() time.Time {
+	return epoch.Add(time.Duration(r.createdAt))
 }
 
 var (
 	ErrMapPointerNil  = errors.New("lazy map pointer nil")
 	ErrMapMutexNil    = errors.New("lazy map mutex nil")
 	ErrValueNotCached = errors.New("value not cached")
+	ErrLoadTimeout    = errors.New("lazy load timed out")
 )
 
 // Value manages a value that is loaded on demand.
 // It guarantees that the initialization function is called only once,
 // even if accessed concurrently.
-// It uses atomic.Value and sync.Mutex for synchronization.
+// It uses an atomic pointer and sync.Mutex for synchronization.
 type Value[T any] struct {
-	val        atomic.Value
-	mu         sync.Mutex
+	val        atomic.Pointer[result[T]]
 	uses       atomic.Int64
 	lastAccess atomic.Int64
+	// locks are the load lock and readyMu. They are the Value's own, allocated with it or on
+	// first use, unless its map was created with WithStripedLocks; see lk.
+	locks atomic.Pointer[loadLocks]
+	// ext holds the state few Values need, allocated the first time one does; see valueExt.
+	ext atomic.Pointer[valueExt[T]]
+	// flags holds the Value's boolean state; see flagPinned and the rest.
+	flags atomic.Uint32
+	// generation is the LazyMap generation the Value was created or last reset in; see LazyMap.Bump.
+	generation atomic.Uint32
+}
+
+// valueExt is the part of a Value that most Values never need. Keeping it out of Value saves
+// memory in large caches, at the cost of a second allocation for the Values that do need it.
+type valueExt[T any] struct {
+	// source loads the value when Load is called without a function; see MapValue. It is set
+	// before the Value is shared and never changed.
+	source func() (T, error)
+	// clock is the TimeSource for timestamps; see WithTimeSource. Like source, it is set before
+	// the Value is shared. Nil means the system clock.
+	clock TimeSource
+	// scope is the context the current result is valid for, if any; see LazyMap.GetContext.
+	scope atomic.Pointer[context.Context]
+	// ready is closed once a result has been stored; see Wait. It is only created while no
+	// result is held. The locks' readyMu guards it and orders result changes with it.
+	ready chan struct{}
+	// flight is the load shared by MapContext callers, if one is running. Guarded by readyMu.
+	flight *sharedLoad[T]
+}
+
+// The bits of Value.flags.
+const (
+	// flagPinned exempts the entry from eviction; see LazyMap.Pin.
+	flagPinned uint32 = 1 << iota
+	// flagRefreshing is set while a background refresh of the value is in flight, or for good on
+	// a refreshed value whose deadline the refresh didn't move; see maybeRefreshAhead.
+	flagRefreshing
+	// flagFetched is set when the result came from a load, which counts as the value's first
+	// use, rather than being set directly; see reads.
+	flagFetched
+	// flagUntracked disables counting uses; see WithoutUsageTracking. It is set before the
+	// Value is shared and never changed.
+	flagUntracked
+	// flagGuarded enables detecting recursive loads; see WithRecursionGuard. Like
+	// flagUntracked, it is set before the Value is shared.
+	flagGuarded
+	// flagInLoad is set while a guarded load of the Value holds its load lock, so that a fetch
+	// loading its own key can be told apart from one loading another key sharing the lock;
+	// see lockLoad.
+	flagInLoad
+)
+
+// has reports whether flag f is set.
+func (l *Value[T]) has(f uint32) bool {
+	return l.flags.Load()&f != 0
+}
+
+// mark sets flag f and reports whether it was clear.
+func (l *Value[T]) mark(f uint32) bool {
+	return l.flags.Or(f)&f == 0
+}
+
+// unmark clears flag f.
+func (l *Value[T]) unmark(f uint32) {
+	l.flags.And(^f)
+}
+
+// markIf sets flag f if on is true and clears it otherwise.
+func (l *Value[T]) markIf(f uint32, on bool) {
+	switch {
+	case l.has(f) == on:
+	case on:
+		l.mark(f)
+	default:
+		l.unmark(f)
+	}
+}
+
+// extOf returns the Value's valueExt, allocating it on first use.
+func (l *Value[T]) extOf() *valueExt[T] {
+	if e := l.ext.Load(); e != nil {
+		return e
+	}
+	l.ext.CompareAndSwap(nil, new(valueExt[T]))
+	return l.ext.Load()
+}
+
+// source returns the function set by MapValue, or nil.
+func (l *Value[T]) source() func() (T, error) {
+	if e := l.ext.Load(); e != nil {
+		return e.source
+	}
+	return nil
+}
+
+// sharedLoad is a load run on behalf of one or more MapContext callers.
+type sharedLoad[T any] struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	// waiters is the number of callers still waiting; abandoned is set when it drops to zero.
+	// Both are guarded by the Value's readyMu.
+	waiters   int
+	abandoned bool
+	// linger keeps the load running for later callers once every waiter has given up.
+	linger bool
+	value  T
+	err    error
+}
+
+// closedReady is shared by every Value whose result was stored before anyone waited on it.
+var closedReady = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// newResult returns a result holding value and err, created now.
+func (l *Value[T]) newResult(value T, err error) *result[T] {
+	return &result[T]{value: value, err: err, createdAt: int64(l.now().Sub(epoch))}
+}
+
+// store sets the result and wakes any callers blocked in Wait. fetched marks a result that came
+// from a load.
+func (l *Value[T]) store(r *result[T], fetched bool) {
+	lk := l.lk()
+	lk.readyMu.Lock()
+	defer lk.readyMu.Unlock()
+	l.markIf(flagFetched, fetched)
+	l.val.Store(r)
+	if e := l.ext.Load(); e != nil && e.ready != nil {
+		close(e.ready)
+		e.ready = nil
+	}
+}
+
+// Invalidate discards the loaded result, along with the usage count and last access time,
+// so that the next Load runs its function again. The Value keeps its identity, so it can stay
+// in a map while it is reloaded. A load already in progress is unaffected and stores its
+// result as usual.
+// Safe for concurrent use.
+func (l *Value[T]) Invalidate() {
+	l.reset()
+}
+
+// reset invalidates the Value and returns a detached Value holding the discarded result,
+// or nil if it wasn't loaded.
+func (l *Value[T]) reset() *Value[T] {
+	old := l.val.Swap(nil)
+	l.uses.Store(0)
+	l.lastAccess.Store(0)
+	e := l.ext.Load()
+	if e != nil {
+		e.scope.Store(nil)
+	}
+	if old == nil {
+		return nil
+	}
+	detached := &Value[T]{}
+	if e != nil && e.clock != nil {
+		detached.ext.Store(&valueExt[T]{clock: e.clock})
+	}
+	detached.val.Store(old)
+	return detached
+}
+
+// readyChan returns a channel that is closed once a result has been stored.
+func (l *Value[T]) readyChan() chan struct{} {
+	lk := l.lk()
+	lk.readyMu.Lock()
+	defer lk.readyMu.Unlock()
+	if l.val.Load() != nil {
+		return closedReady
+	}
+	e := l.extOf()
+	if e.ready == nil {
+		e.ready = make(chan struct{})
+	}
+	return e.ready
 }
 
 // Load ensures the value is loaded by executing fn if it hasn't been loaded yet.
 // Subsequent calls return the cached value and error.
+// If fn itself calls Load on the same Value, that call deadlocks, unless the Value belongs to a
+// map using WithRecursionGuard, in which case it returns ErrRecursiveLoad.
+// A Value created by MapValue always loads from its source, so fn is ignored and may be nil;
+// for any other Value that isn't loaded yet, Load(nil) returns ErrValueNotCached.
 // Safe for concurrent use.
 func (l *Value[T]) Load(fn func() (T, error)) (T, error) {
 	if v := l.val.Load(); v != nil {
-		l.uses.Add(1)
-		l.updateLastAccess()
-		r := v.(*result[T])
+		l.used()
+		r := v
 		return r.value, r.err
 	}
-	l.mu.Lock()
-	defer l.mu.Unlock()
+	if src := l.source(); src != nil {
+		fn = src
+	}
+	if fn == nil {
+		var zero T
+		return zero, ErrValueNotCached
+	}
+	held, err := l.lockLoad()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer l.unlockLoad(held)
 	if v := l.val.Load(); v != nil {
-		l.uses.Add(1)
-		l.updateLastAccess()
-		r := v.(*result[T])
+		l.used()
+		r := v
 		return r.value, r.err
 	}
 	val, err := fn()
-	l.val.Store(&result[T]{value: val, err: err, createdAt: time.Now()})
-	l.uses.Add(1)
-	l.updateLastAccess()
+	if l.unready(err) {
+		return val, err
+	}
+	l.store(l.newResult(val, err), true)
+	l.used()
+	return val, err
+}
+
+// LoadWithTimeout is like Load, but gives up waiting after d and returns ErrLoadTimeout.
+// fn keeps running after a timeout; when it completes its result is stored as with Load,
+// so the work isn't wasted and later calls see the value. The once-guarantee still holds:
+// callers arriving while fn is running wait for that same call rather than starting another.
+// Safe for concurrent use.
+func (l *Value[T]) LoadWithTimeout(d time.Duration, fn func() (T, error)) (T, error) {
+	if v := l.val.Load(); v != nil {
+		l.used()
+		r := v
+		return r.value, r.err
+	}
+	done := make(chan *result[T], 1)
+	go func() {
+		val, err := l.Load(fn)
+		done <- &result[T]{value: val, err: err}
+	}()
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-t.C:
+		var zero T
+		return zero, ErrLoadTimeout
+	}
+}
+
+// LoadContext is like Load, but passes a context to fn and stops waiting once ctx is done,
+// returning ctx.Err() without caching anything, so a later call can still load the value.
+// A loaded value is returned straight away whatever the state of ctx. As with MapContext, the
+// load is shared by every LoadContext caller that arrives while it runs: fn receives a context
+// carrying ctx's values that is only canceled once all of them have given up, so one caller
+// canceling doesn't fail the others. An error from a load every caller gave up on isn't cached.
+// Safe for concurrent use.
+func (l *Value[T]) LoadContext(ctx context.Context, fn func(context.Context) (T, error)) (T, error) {
+	var zero T
+	if r := l.val.Load(); r != nil {
+		l.used()
+		return r.value, r.err
+	}
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	if src := l.source(); src != nil {
+		fn = func(context.Context) (T, error) { return src() }
+	}
+	if fn == nil {
+		return zero, ErrValueNotCached
+	}
+	if l.loading() {
+		// The shared load would wait for the lock held by this goroutine's own Load.
+		return zero, ErrRecursiveLoad
+	}
+	fetchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	return l.loadShared(ctx, cancel, false, func() (T, error) { return fn(fetchCtx) })
+}
+
+// loadShared is like Load, but the caller stops waiting once ctx is done, returning ctx.Err().
+// fn runs in its own goroutine and is shared by every loadShared caller that arrives while it runs.
+// cancel cancels the context fn uses; it is called once every caller has given up, or straight
+// away if fn isn't needed. An error returned by a fetch that every caller gave up on is not
+// cached, so the next call fetches again; any other result is stored as with Load. If linger is
+// set the load is never given up on: it keeps running for later callers and its result is stored.
+func (l *Value[T]) loadShared(ctx context.Context, cancel context.CancelFunc, linger bool, fn func() (T, error)) (T, error) {
+	if r := l.val.Load(); r != nil {
+		cancel()
+		l.used()
+		return r.value, r.err
+	}
+	lk := l.lk()
+	if l.has(flagGuarded) && !l.has(flagInLoad) && lk.heldByCaller() {
+		// This goroutine is loading another key that shares l's striped lock, which the shared
+		// load's goroutine would wait for forever, so load inline instead.
+		defer cancel()
+		return l.Load(fn)
+	}
+	lk.readyMu.Lock()
+	e := l.extOf()
+	f := e.flight
+	if f != nil && l.loading() {
+		// The shared load's own fetch is loading this key, so joining it would never return.
+		lk.readyMu.Unlock()
+		cancel()
+		var zero T
+		return zero, ErrRecursiveLoad
+	}
+	if f == nil {
+		f = &sharedLoad[T]{done: make(chan struct{}), cancel: cancel, linger: linger}
+		e.flight = f
+		go l.runShared(f, fn)
+	} else {
+		cancel()
+	}
+	f.waiters++
+	lk.readyMu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		lk.readyMu.Lock()
+		f.waiters--
+		if f.waiters == 0 && !f.abandoned && !f.linger {
+			f.abandoned = true
+			// Later callers start a fresh load rather than joining this canceled one.
+			if e.flight == f {
+				e.flight = nil
+			}
+			f.cancel()
+		}
+		lk.readyMu.Unlock()
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// runShared runs fn for f under the load lock.
+func (l *Value[T]) runShared(f *sharedLoad[T], fn func() (T, error)) {
+	defer close(f.done)
+	// runShared has a goroutine of its own, so it can't already hold the lock.
+	held, _ := l.lockLoad()
+	defer l.unlockLoad(held)
+	lk := l.lk()
+	if r := l.val.Load(); r != nil {
+		f.value, f.err = r.value, r.err
+	} else {
+		f.value, f.err = fn()
+		lk.readyMu.Lock()
+		abandoned := f.abandoned
+		lk.readyMu.Unlock()
+		if (f.err == nil || !abandoned) && !l.unready(f.err) {
+			l.store(l.newResult(f.value, f.err), true)
+		}
+	}
+	l.used()
+	lk.readyMu.Lock()
+	if e := l.ext.Load(); e.flight == f {
+		e.flight = nil
+	}
+	lk.readyMu.Unlock()
+	f.cancel()
+}
+
+// LoadRetryable is like Load, except that a cached error is not final: if the value was loaded
+// with an error, fn is run again. A successful result is cached as with Load.
+// Callers that were waiting on an attempt in progress share its result, even if it failed,
+// so a single failing attempt is not retried once per waiter.
+// Safe for concurrent use.
+func (l *Value[T]) LoadRetryable(fn func() (T, error)) (T, error) {
+	seen := l.val.Load()
+	if seen != nil {
+		if r := seen; r.err == nil {
+			l.used()
+			return r.value, nil
+		}
+	}
+	held, err := l.lockLoad()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer l.unlockLoad(held)
+	if v := l.val.Load(); v != nil {
+		// A different result means another attempt finished while we were waiting.
+		if r := v; r.err == nil || v != seen {
+			l.used()
+			return r.value, r.err
+		}
+	}
+	val, err := fn()
+	l.store(l.newResult(val, err), true)
+	l.used()
 	return val, err
 }
 
+// reload runs fn and replaces the stored result, even if the value is already loaded.
+// If the new value equals the cached one according to eq, the existing result is kept
+// so that no new result is allocated.
+// If keepOnError is set and fn fails, a previously loaded value is kept and returned instead.
+// displaced is the result replaced, if any.
+func (l *Value[T]) reload(fn func() (T, error), eq func(a, b T) bool, keepOnError bool) (v T, displaced *result[T], err error) {
+	held, err := l.lockLoad()
+	if err != nil {
+		return v, nil, err
+	}
+	defer l.unlockLoad(held)
+	val, err := fn()
+	old := l.val.Load()
+	if old != nil && old.err == nil && (err == nil && eq(old.value, val) || err != nil && keepOnError) {
+		l.used()
+		return old.value, nil, nil
+	}
+	l.store(l.newResult(val, err), true)
+	l.used()
+	return val, old, err
+}
+
 // Set manually sets the value if it hasn't been loaded yet.
 // If the value is already loaded (via Load or Set), this operation is a no-op.
 // Safe for concurrent use.
@@ -64,19 +471,84 @@ func (l *Value[T]) Set(v T) {
 	if l.val.Load() != nil {
 		return
 	}
-	l.mu.Lock()
-	defer l.mu.Unlock()
+	held := l.lockWrite()
+	defer l.unlockWrite(held)
 	if l.val.Load() != nil {
 		return
 	}
-	l.val.Store(&result[T]{value: v, err: nil, createdAt: time.Now()})
+	l.store(l.newResult(v, nil), false)
 	l.updateLastAccess()
 }
 
+// setIfAbsent sets the value if it hasn't been loaded yet and reports whether it did.
+// It returns the value now held, which is the existing value if one was already loaded.
+func (l *Value[T]) setIfAbsent(v T) (T, bool) {
+	if r := l.val.Load(); r != nil {
+		return r.value, false
+	}
+	held := l.lockWrite()
+	defer l.unlockWrite(held)
+	if r := l.val.Load(); r != nil {
+		return r.value, false
+	}
+	l.store(l.newResult(v, nil), false)
+	l.updateLastAccess()
+	return v, true
+}
+
+// Overwrite unconditionally replaces the value, with a fresh CreatedAt, even if it is already
+// loaded. This deliberately breaks the once-guarantee: callers that already read the old value
+// keep it, and later calls see v. Use it when the caller knows the cached value is stale, for
+// example to write through after updating the source. If a load is in progress, Overwrite waits
+// for it and then replaces its result.
+// Safe for concurrent use.
+func (l *Value[T]) Overwrite(v T) {
+	l.overwrite(v)
+}
+
+// overwrite is Overwrite, returning the result it replaced, if any.
+func (l *Value[T]) overwrite(v T) *result[T] {
+	held := l.lockWrite()
+	defer l.unlockWrite(held)
+	old := l.val.Load()
+	l.store(l.newResult(v, nil), false)
+	l.updateLastAccess()
+	return old
+}
+
+// CompareAndRefresh replaces the value with next, like Overwrite, but only if the value is
+// loaded without an error and eq reports it equal to old. It reports whether the value was
+// replaced, so a caller can read, compute and write back without losing a concurrent update.
+// Safe for concurrent use.
+func (l *Value[T]) CompareAndRefresh(old T, eq func(a, b T) bool, next T) bool {
+	held := l.lockWrite()
+	defer l.unlockWrite(held)
+	r := l.val.Load()
+	if r == nil || r.err != nil || !eq(r.value, old) {
+		return false
+	}
+	l.store(l.newResult(next, nil), false)
+	l.updateLastAccess()
+	return true
+}
+
 // Store forcibly sets the value, bypassing the "once" check.
 // This is used internally to overwrite an error state with a default value.
 func (l *Value[T]) Store(v T) {
-	l.val.Store(&result[T]{value: v, err: nil, createdAt: time.Now()})
+	l.store(l.newResult(v, nil), false)
+	l.updateLastAccess()
+}
+
+// SetError forcibly stores err as the result, with the zero value and a fresh CreatedAt, like
+// Store does for a value. Later Loads return err without running their function, which lets
+// callers inject a known failure such as an open circuit breaker. LoadRetryable, and Map with
+// WithRetryOnError, treat it like any cached error and fetch again.
+// Safe for concurrent use.
+func (l *Value[T]) SetError(err error) {
+	held := l.lockWrite()
+	defer l.unlockWrite(held)
+	var zero T
+	l.store(l.newResult(zero, err), false)
 	l.updateLastAccess()
 }
 
@@ -85,9 +557,8 @@ func (l *Value[T]) Store(v T) {
 // Safe for concurrent use.
 func (l *Value[T]) Peek() (T, bool) {
 	if v := l.val.Load(); v != nil {
-		l.uses.Add(1)
-		l.updateLastAccess()
-		r := v.(*result[T])
+		l.used()
+		r := v
 		return r.value, true
 	}
 	var zero T
@@ -95,22 +566,48 @@ func (l *Value[T]) Peek() (T, bool) {
 }
 
 // CreatedAt returns the time when the value was loaded.
+// It is fixed once loaded: accesses don't change it, only a new load does.
 // Returns zero time if not loaded.
 func (l *Value[T]) CreatedAt() time.Time {
-	if v := l.val.Load(); v != nil {
-		r := v.(*result[T])
-		return r.createdAt
+	if r := l.val.Load(); r != nil {
+		return r.created()
 	}
 	return time.Time{}
 }
 
+// Age returns how long ago the value was loaded, by the TimeSource of its map (see
+// WithTimeSource), or zero if it isn't loaded.
+func (l *Value[T]) Age() time.Duration {
+	if r := l.val.Load(); r != nil {
+		return l.now().Sub(r.created())
+	}
+	return 0
+}
+
 // Uses returns the number of times the value has been accessed.
 func (l *Value[T]) Uses() int64 {
 	return l.uses.Load()
 }
 
+// reads returns the number of uses of the current result other than the load that produced it.
+func (l *Value[T]) reads() int64 {
+	uses := l.uses.Load()
+	if r := l.val.Load(); r != nil && l.has(flagFetched) {
+		uses--
+	}
+	return max(uses, 0)
+}
+
+// ResetUses sets the use count back to zero without reloading the value, renewing it under
+// ExpireAfterUses. Uses counted concurrently with the reset may land either side of it.
+// Safe for concurrent use.
+func (l *Value[T]) ResetUses() {
+	l.uses.Store(0)
+}
+
 // LastAccess returns the time when the value was last accessed.
-// Returns zero time if not loaded.
+// It is updated by Load, Peek and the other methods that count as a use, as well as when the
+// value is set. Returns zero time if not loaded.
 func (l *Value[T]) LastAccess() time.Time {
 	if v := l.lastAccess.Load(); v != 0 {
 		return time.Unix(0, v)
@@ -118,21 +615,68 @@ func (l *Value[T]) LastAccess() time.Time {
 	return time.Time{}
 }
 
+// used records an access, counting it as a use unless usage tracking is off.
+func (l *Value[T]) used() {
+	if !l.has(flagUntracked) {
+		l.uses.Add(1)
+	}
+	l.updateLastAccess()
+}
+
 func (l *Value[T]) updateLastAccess() {
-	l.lastAccess.Store(time.Now().UnixNano())
+	l.lastAccess.Store(l.now().UnixNano())
 }
 
 // Value returns the cached value, true if loaded, and error if any.
 // Unlike Peek or Load, this method does not increment the usage count.
 func (l *Value[T]) Value() (T, bool, error) {
 	if v := l.val.Load(); v != nil {
-		r := v.(*result[T])
+		r := v
 		return r.value, true, r.err
 	}
 	var zero T
 	return zero, false, nil
 }
 
+// Wait blocks until the value has been loaded by another caller, through Load, Set or similar,
+// and returns its value and error. Unlike Load it never runs a fetch itself, and unlike Peek
+// it doesn't return early while a load is in progress. If ctx is done first, Wait returns
+// ctx.Err(). Like Value, it does not count as a use.
+// Safe for concurrent use.
+func (l *Value[T]) Wait(ctx context.Context) (T, error) {
+	for {
+		if r := l.val.Load(); r != nil {
+			return r.value, r.err
+		}
+		// The value may have been invalidated again by the time we look, so wait for the next result.
+		select {
+		case <-l.readyChan():
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// loadedOK reports whether the value is loaded without an error.
+func (l *Value[T]) loadedOK() bool {
+	_, loaded, err := l.Value()
+	return loaded && err == nil
+}
+
+// Err returns the error the value was loaded with, or nil if it loaded successfully or
+// isn't loaded. Like Value, it does not count as a use.
+func (l *Value[T]) Err() error {
+	_, _, err := l.Value()
+	return err
+}
+
+// HasError reports whether the value is loaded with an error.
+// Like Value, it does not count as a use.
+func (l *Value[T]) HasError() bool {
+	return l.Err() != nil
+}
+
 // IsLoaded returns true if the value has been loaded.
 func (l *Value[T]) IsLoaded() bool {
 	return l.val.Load() != nil
@@ -140,17 +684,92 @@ func (l *Value[T]) IsLoaded() bool {
 
 // args holds the configuration for Map operations.
 type args[K comparable, V any] struct {
-	dontFetch      bool
-	refresh        bool
-	clear          bool
-	must           bool
-	mustCached     bool
-	setID          *K
-	setValue       *V
-	defaultValue   *V
-	maxSize        int
-	evictionPolicy EvictionPolicy[K, V]
-	expiry         Expiry[V]
+	dontFetch       bool
+	refresh         bool
+	clear           bool
+	must            bool
+	mustCached      bool
+	setID           *K
+	setValue        *V
+	defaultValue    *V
+	defaultFunc     func(K, error) (V, bool)
+	maxSize         int
+	evictionPolicy  EvictionPolicy[K, V]
+	expiry          Expiry[V]
+	hasher          Hasher[K]
+	asyncEviction   *asyncEvictor[K, V]
+	equal           func(a, b V) bool
+	setLoaded       *bool
+	valueDest       **Value[V]
+	removalLog      *removalLog[K]
+	refreshAhead    time.Duration
+	refreshSem      chan struct{}
+	highWater       *highWaterMark
+	noUsageTracking bool
+	skipBusyRefresh bool
+	keepOnError     bool
+	onEvict         func(K, V)
+	onExpire        func(context.Context, K, V, string)
+	callbackCtx     context.Context
+	retryOnError    bool
+	retryAttempts   int
+	retryBackoff    func(attempt int) time.Duration
+	retryPolicy     RetryPolicy
+	concurrency     int
+	fallback        func(K) (V, bool, error)
+	loaderGroup     *LoaderGroup[K, V]
+	writeBack       func(K, V)
+	metrics         Metrics[K]
+	logger          Logger
+	onLoadStart     func(K)
+	onLoadEnd       func(K, V, error, time.Duration)
+	recover         bool
+	initialCapacity int
+	// timeout bounds how long a cold load is waited for; see WithTimeout.
+	timeout          time.Duration
+	abandonOnTimeout bool
+	// stats counts cache events for LazyMap.Stats.
+	stats *statsCounters
+	// events publishes changes to LazyMap.Subscribe subscribers.
+	events *eventHub[K, V]
+	// coalesceWindow is the minimum time between Refresh fetches of a key; see WithCoalesceWindow.
+	coalesceWindow time.Duration
+	// sourceDest receives where the returned value came from; see LazyMap.GetWithSource.
+	sourceDest *Source
+	// generation is the map's invalidation counter; see LazyMap.Bump.
+	generation *atomic.Uint32
+	// clock is the TimeSource given to new Values.
+	clock TimeSource
+	// negativeTTL is how long a not-found result is cached; see WithNegativeCache.
+	negativeTTL  time.Duration
+	autoClose    bool
+	onCloseError func(K, error)
+	// fetchPrev is the fetch function passed to MapPrev.
+	fetchPrev func(K, V, bool) (V, error)
+	// defaultFetch is used when no fetch function is passed; see WithDefaultFetch.
+	defaultFetch func(K) (V, error)
+	// copyOnWrite is set by WithCopyOnWrite; cow is the published copy NewLazyMap adds for it.
+	copyOnWrite bool
+	cow         *cowMap[K, V]
+	// merge combines a refetched value with the one it replaces; see WithMerge.
+	merge func(old, new V) V
+	// stripes are the load locks shared by new Values; see WithStripedLocks.
+	stripes        []loadLocks
+	recursionGuard bool
+	// ctx is the context fetches run with under MapContext, if any. It is derived from
+	// waitCtx, the caller's context, but is only canceled by cancelFetch.
+	ctx         context.Context
+	waitCtx     context.Context
+	cancelFetch context.CancelFunc
+}
+
+// buildArgs applies opts in order and returns the resulting configuration.
+func buildArgs[K comparable, V any](opts []Option[K, V]) *args[K, V] {
+	a := &args[K, V]{}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 // Option configures the behavior of the Map function.
@@ -179,22 +798,72 @@ func MustBeCached[K comparable, V any]() Option[K, V] {
 	return func(a *args[K, V]) { a.mustCached = true }
 }
 
-// Must returns an Option that wraps any error returned by the fetch function.
+// FetchError is the error returned when Must is in effect and the fetch for Key failed with Err.
+// It is returned by value, so use a FetchError[K] target with errors.As.
+type FetchError[K comparable] struct {
+	Key K
+	Err error
+}
+
+func (e FetchError[K]) Error() string {
+	return fmt.Sprintf("fetch error for key %v: %v", e.Key, e.Err)
+}
+
+func (e FetchError[K]) Unwrap() error {
+	return e.Err
+}
+
+// Must returns an Option that wraps any error returned by the fetch function in a FetchError.
 func Must[K comparable, V any]() Option[K, V] { return func(a *args[K, V]) { a.must = true } }
 
 // DefaultValue returns an Option that specifies a fallback value to return if the value is not found
 // (when DontFetch is used) or if fetching fails (unless Must is also used).
+// v may be nil for pointer and interface types. Note that a fetch returning a nil value with no
+// error caches that nil like any other value: later lookups are hits and don't use the default.
 func DefaultValue[K comparable, V any](v V) Option[K, V] {
 	return func(a *args[K, V]) { a.defaultValue = &v }
 }
 
+// WithDefaultFunc returns an Option that decides the fallback for a failed fetch from the key and
+// error. If fn returns true, its value is returned and cached as with DefaultValue; if it returns
+// false, the error is returned. It takes precedence over DefaultValue for fetch errors, and like
+// DefaultValue it is not consulted when Must is used.
+func WithDefaultFunc[K comparable, V any](fn func(K, error) (V, bool)) Option[K, V] {
+	return func(a *args[K, V]) { a.defaultFunc = fn }
+}
+
+// fetchDefault returns the fallback for a fetch of id that failed with err, if there is one.
+func (a *args[K, V]) fetchDefault(id K, err error) (V, bool) {
+	if a.must {
+		var zero V
+		return zero, false
+	}
+	if a.defaultFunc != nil {
+		return a.defaultFunc(id, err)
+	}
+	if a.defaultValue != nil {
+		return *a.defaultValue, true
+	}
+	var zero V
+	return zero, false
+}
+
 // MaxSize returns an Option that limits the size of the map.
 // If the map reaches the specified size, adding a new item will cause an existing item to be evicted.
+// If the map is larger than size, for example because earlier calls used a larger MaxSize, the
+// insert evicts as many items as needed to get back within it, unless the policy runs out of victims.
 // The default eviction policy is RandomEvictionPolicy.
 func MaxSize[K comparable, V any](size int) Option[K, V] {
 	return func(a *args[K, V]) { a.maxSize = size }
 }
 
+// WithInitialCapacity returns an Option that pre-sizes the map to hold n entries, avoiding
+// rehashing while a cache of known size fills up. It applies when the map is created: by
+// NewLazyMap, by NewShardedLazyMap (split across the shards), or by Map when given a nil map.
+func WithInitialCapacity[K comparable, V any](n int) Option[K, V] {
+	return func(a *args[K, V]) { a.initialCapacity = n }
+}
+
 // WithEvictionPolicy returns an Option that specifies the eviction policy to use when MaxSize is reached.
 func WithEvictionPolicy[K comparable, V any](policy EvictionPolicy[K, V]) Option[K, V] {
 	return func(a *args[K, V]) { a.evictionPolicy = policy }
@@ -205,6 +874,95 @@ func WithExpiry[K comparable, V any](policy Expiry[V]) Option[K, V] {
 	return func(a *args[K, V]) { a.expiry = policy }
 }
 
+// WithAsyncEviction returns an Option that moves MaxSize eviction off the inserting caller.
+// Inserts proceed immediately, letting the map briefly exceed MaxSize, and a background goroutine
+// trims it back down. Pending trims are coalesced into a single goroutine, and if the map overshoots
+// by more than a tenth of MaxSize (at least one entry) the insert falls back to evicting synchronously.
+// The returned Option carries the coalescing state, so reuse the same Option value across calls
+// (as LazyMap does with its default options).
+func WithAsyncEviction[K comparable, V any]() Option[K, V] {
+	ae := &asyncEvictor[K, V]{}
+	return func(a *args[K, V]) { a.asyncEviction = ae }
+}
+
+// WithEqual returns an Option that makes Refresh reload an existing entry in place.
+// If the freshly fetched value equals the cached one according to eq, the existing result
+// (including its CreatedAt) is kept rather than allocating a new one, reducing allocation churn
+// for frequently refreshed but stable data. If eq is nil, reflect.DeepEqual is used.
+func WithEqual[K comparable, V any](eq func(a, b V) bool) Option[K, V] {
+	if eq == nil {
+		eq = func(a, b V) bool { return reflect.DeepEqual(a, b) }
+	}
+	return func(a *args[K, V]) { a.equal = eq }
+}
+
+// WithRetryOnError returns an Option under which a fetch error is not cached as a final result:
+// the next Map call for the key runs fetch again. Concurrent callers still share a single
+// in-flight attempt. With DefaultValue the default is returned on failure but not cached, and
+// with Must the wrapped error is returned, so in both cases a later call retries the fetch.
+func WithRetryOnError[K comparable, V any]() Option[K, V] {
+	return func(a *args[K, V]) { a.retryOnError = true }
+}
+
+// WithKeepOnRefreshError returns an Option that keeps the previously loaded value when a
+// Refresh fails. The new value is only swapped into the map once its fetch succeeds; on failure
+// the prior value is returned without an error and stays cached, rather than being replaced by
+// the error or a DefaultValue.
+func WithKeepOnRefreshError[K comparable, V any]() Option[K, V] {
+	return func(a *args[K, V]) { a.keepOnError = true }
+}
+
+// WithoutUsageTracking returns an Option under which the map's entries don't count their uses,
+// saving an atomic increment on every access for read-heavy caches that don't need the count.
+// Uses then stays 0, so ExpireAfterUses and ExpireAfterReads never expire and ExpireWhen sees
+// no uses. LastAccess is still recorded, and eviction policies such as LFU, which keep their
+// own counts, are unaffected. It applies to entries created while it is in effect.
+func WithoutUsageTracking[K comparable, V any]() Option[K, V] {
+	return func(a *args[K, V]) { a.noUsageTracking = true }
+}
+
+// newValue returns a new, empty Value for key under the map's configuration.
+func (a *args[K, V]) newValue(key K) *Value[V] {
+	var lv *Value[V]
+	if a.stripes != nil {
+		lv = &Value[V]{}
+		lv.locks.Store(a.stripe(key))
+	} else {
+		o := &ownedValue[V]{}
+		lv = &o.v
+		lv.locks.Store(&o.own)
+	}
+	if a.noUsageTracking {
+		lv.mark(flagUntracked)
+	}
+	if a.recursionGuard {
+		lv.mark(flagGuarded)
+	}
+	if a.clock != nil {
+		lv.ext.Store(&valueExt[V]{clock: a.clock})
+	}
+	a.stamp(lv)
+	return lv
+}
+
+// WithForceFetch returns an Option that always calls fetch, ignoring any cached value, but
+// keeps the cached value if the fetch fails: a best-effort refresh that never replaces a good
+// value with an error. It is Refresh combined with WithKeepOnRefreshError.
+func WithForceFetch[K comparable, V any]() Option[K, V] {
+	return func(a *args[K, V]) {
+		a.refresh = true
+		a.keepOnError = true
+	}
+}
+
+// WithValueDest returns an Option that stores the *Value used for the key into dst.
+// The pointer is the same one held in the map, so it can be used later to inspect
+// CreatedAt, Uses and so on without going through Map again.
+// It is not set when Clear is used.
+func WithValueDest[K comparable, V any](dst **Value[V]) Option[K, V] {
+	return func(a *args[K, V]) { a.valueDest = dst }
+}
+
 // Map retrieves or creates a lazy Value in the provided map.
 // It handles locking the map using the provided mutex.
 //
@@ -217,11 +975,51 @@ func WithExpiry[K comparable, V any](policy Expiry[V]) Option[K, V] {
 //
 // Returns the value and any error encountered.
 func Map[K comparable, V any](m *map[K]*Value[V], mu *sync.RWMutex, id K, fetch func(K) (V, error), opts ...Option[K, V]) (V, error) {
-	var zero V
-	args := &args[K, V]{}
-	for _, opt := range opts {
-		opt(args)
+	return mapWith(m, mu, id, fetch, buildArgs(opts))
+}
+
+// MapPrev is like Map, but fetch also receives the value being replaced when an entry is
+// reloaded, e.g. after it expired or because of Refresh, so that it can return the previous
+// value when nothing has changed, as with a conditional GET. hadPrev is false on the first load,
+// when there is no previous value, or when the previous load failed.
+func MapPrev[K comparable, V any](m *map[K]*Value[V], mu *sync.RWMutex, id K, fetch func(id K, prev V, hadPrev bool) (V, error), opts ...Option[K, V]) (V, error) {
+	args := buildArgs(opts)
+	args.fetchPrev = fetch
+	var f func(K) (V, error)
+	if fetch != nil {
+		// Used where no previous value is tracked, e.g. by WithRefreshAhead.
+		f = func(k K) (V, error) {
+			var zero V
+			return fetch(k, zero, false)
+		}
 	}
+	return mapWith(m, mu, id, f, args)
+}
+
+// MapContext is like Map, but fetch receives a context, and the call returns ctx.Err() as soon as
+// ctx is done, even if the fetch is still running.
+// Concurrent MapContext calls for the same key share one fetch, so one caller canceling doesn't
+// fail the others: the fetch's context carries ctx's values, but it is only canceled once every
+// caller waiting on it has given up. Retries configured with WithRetry stop at that point too.
+// A fetch that outlives its callers still stores its value for later calls, unless it fails,
+// in which case the error is not cached.
+func MapContext[K comparable, V any](ctx context.Context, m *map[K]*Value[V], mu *sync.RWMutex, id K, fetch func(context.Context, K) (V, error), opts ...Option[K, V]) (V, error) {
+	args := buildArgs(opts)
+	fetchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	args.ctx = fetchCtx
+	args.waitCtx = ctx
+	args.cancelFetch = cancel
+	var f func(K) (V, error)
+	if fetch != nil {
+		f = func(k K) (V, error) { return fetch(fetchCtx, k) }
+	}
+	return mapWith(m, mu, id, f, args)
+}
+
+// mapWith implements Map for an already built configuration.
+// args is treated as read-only so that a LazyMap can share one across calls.
+func mapWith[K comparable, V any](m *map[K]*Value[V], mu *sync.RWMutex, id K, fetch func(K) (V, error), args *args[K, V]) (V, error) {
+	var zero V
 	if args.setID != nil {
 		id = *args.setID
 	}
@@ -231,8 +1029,30 @@ func Map[K comparable, V any](m *map[K]*Value[V], mu *sync.RWMutex, id K, fetch
 	if mu == nil {
 		return zero, ErrMapMutexNil
 	}
+	if err := args.checkRefreshAhead(); err != nil {
+		return zero, err
+	}
+	if fetch == nil {
+		fetch = args.defaultFetch
+	}
 
 	var lv *Value[V]
+	// reloadInPlace is set when a Refresh reuses the existing Value (see WithEqual).
+	var reloadInPlace bool
+	// removals collects entries removed under the write lock, reported once it's released.
+	var removals []removal[K, V]
+	// previous is the entry being refreshed when WithKeepOnRefreshError is set.
+	// The new value is loaded detached from the map and only swapped in if the fetch succeeds.
+	var previous *Value[V]
+	// seen is the expired result found under the read lock. If the entry holds a different
+	// result by the time the write lock is held, another caller has already reloaded it.
+	var seen *result[V]
+	// prior holds the value being reloaded, if any, for fetches from MapPrev and WithMerge.
+	var prior *Value[V]
+	// crossedSize is the size after an insert that crossed the high-water mark, if one did.
+	var crossedSize int
+	// isNew is set when the key wasn't in the map, for InsertAwareEvictionPolicy.
+	var isNew bool
 
 	mu.RLock()
 	if args.clear {
@@ -240,8 +1060,9 @@ func Map[K comparable, V any](m *map[K]*Value[V], mu *sync.RWMutex, id K, fetch
 		goto WriteLock
 	}
 	if *m != nil {
-		if val, ok := (*m)[id]; ok && !args.refresh {
-			if args.expiry != nil && val.IsLoaded() && args.expiry.IsExpired(val) {
+		if val, ok := (*m)[id]; ok && (!args.refresh || args.coalesced(val)) {
+			if val.IsLoaded() && args.isExpired(val) {
+				seen = val.val.Load()
 				mu.RUnlock()
 				goto WriteLock
 			}
@@ -255,63 +1076,134 @@ func Map[K comparable, V any](m *map[K]*Value[V], mu *sync.RWMutex, id K, fetch
 WriteLock:
 	mu.Lock()
 	if *m == nil {
-		*m = make(map[K]*Value[V])
+		*m = make(map[K]*Value[V], args.initialCapacity)
 	}
 	if args.clear {
-		delete(*m, id)
+		if val, ok := (*m)[id]; ok {
+			delete(*m, id)
+			removals = append(removals, removal[K, V]{key: id, value: val, reason: RemovalCleared})
+			args.republish(*m)
+		}
 		mu.Unlock()
+		args.removed(removals)
 		return zero, nil
 	}
-	if val, ok := (*m)[id]; ok && !args.refresh {
+	if val, ok := (*m)[id]; ok && (!args.refresh || args.coalesced(val)) {
 		expired := false
-		if args.expiry != nil && val.IsLoaded() && args.expiry.IsExpired(val) {
+		if val.IsLoaded() && (seen == nil || val.val.Load() == seen) && args.isExpired(val) {
 			expired = true
 		}
 		if expired {
-			delete(*m, id)
-			lv = &Value[V]{}
-			(*m)[id] = lv
+			// Reset the entry in place rather than replacing it, so anything tracking it by
+			// pointer keeps working and no new Value is allocated.
+			// The reason has to be read before the reset clears the usage it may depend on.
+			why := args.expiryReason(val)
+			prior = val.reset()
+			args.stamp(val)
+			removals = append(removals, removal[K, V]{key: id, value: prior, reason: expiredBy(why), why: why})
+			lv = val
 		} else {
 			lv = val
 		}
+	} else if ok && args.equal != nil && val.IsLoaded() {
+		lv = val
+		prior = val
+		reloadInPlace = true
+		args.stamp(val)
+	} else if ok && args.keepOnError && args.setValue == nil && val.loadedOK() {
+		previous = val
+		prior = val
+		lv = args.newValue(id)
+		lv.markIf(flagPinned, val.has(flagPinned))
 	} else {
-		if !ok && args.maxSize > 0 && len(*m) >= args.maxSize {
-			if args.evictionPolicy != nil {
-				victim, found := args.evictionPolicy.SelectVictim(*m)
-				if found {
-					delete(*m, victim)
-				}
+		if ok {
+			prior = val
+			removals = append(removals, removal[K, V]{key: id, value: val, reason: RemovalSwapped})
+		} else if args.maxSize > 0 && len(*m) >= args.maxSize {
+			if args.asyncEviction != nil && len(*m) < args.maxSize+asyncEvictionSlack(args.maxSize) {
+				args.asyncEviction.schedule(m, mu, args, id)
 			} else {
-				// Fallback to random/range if policy is unknown/nil
-				for k := range *m {
-					delete(*m, k)
-					break
+				// Usually one eviction makes room, but a smaller MaxSize than earlier calls
+				// used can leave the map well over it.
+				for len(*m) >= args.maxSize {
+					k, victim, found := evict(*m, args.evictionPolicy, id)
+					if !found {
+						break
+					}
+					removals = append(removals, removal[K, V]{key: k, value: victim, reason: RemovalEvicted})
 				}
 			}
 		}
-		lv = &Value[V]{}
+		lv = args.newValue(id)
+		if ok {
+			lv.markIf(flagPinned, val.has(flagPinned))
+		}
 		(*m)[id] = lv
+		if !ok && args.highWater.inserted(len(*m), args.maxSize) {
+			crossedSize = len(*m)
+		}
+		isNew = !ok
+		args.republish(*m)
 	}
 	mu.Unlock()
+	args.removed(removals)
+	if isNew && args.evictionPolicy != nil {
+		insert(args.evictionPolicy, id)
+	}
+	if crossedSize > 0 {
+		args.highWater.fn(crossedSize, args.maxSize)
+	}
 
 ProcessValue:
+	if args.valueDest != nil {
+		*args.valueDest = lv
+	}
 	if args.setValue != nil {
-		lv.Set(*args.setValue)
+		actual, stored := lv.setIfAbsent(*args.setValue)
+		if stored {
+			args.publish(EventSet, id, actual)
+		}
 		if args.evictionPolicy != nil {
 			args.evictionPolicy.Access(id)
 		}
+		if stored {
+			args.from(SourceSet)
+		} else {
+			args.from(SourceCache)
+		}
+		if args.setLoaded != nil {
+			*args.setLoaded = !stored
+			return actual, nil
+		}
 		return *args.setValue, nil
 	}
 
-	v, loaded := lv.Peek()
-	if loaded {
-		if args.evictionPolicy != nil {
-			args.evictionPolicy.Access(id)
+	var v V
+	var loaded bool
+	// With WithRetryOnError a cached error is not a hit; the fetch is run again below.
+	if !reloadInPlace && !(args.retryOnError && lv.HasError()) {
+		v, loaded = lv.Peek()
+		if loaded {
+			if args.evictionPolicy != nil {
+				args.evictionPolicy.Access(id)
+			}
+			args.hit(id)
+			args.from(SourceCache)
+			// A cached not-found result is an answer in itself, so it is returned as such.
+			if err := lv.Err(); IsNotFound(err) {
+				if args.must {
+					return zero, FetchError[K]{Key: id, Err: err}
+				}
+				return zero, err
+			}
+			maybeRefreshAhead(m, mu, id, lv, fetch, args)
+			return v, nil
 		}
-		return v, nil
 	}
+	args.miss(id)
 
 	if args.dontFetch {
+		args.from(SourceDefault)
 		if args.mustCached && !loaded {
 			return zero, ErrValueNotCached
 		}
@@ -322,21 +1214,71 @@ ProcessValue:
 	}
 
 	if fetch == nil {
+		args.from(SourceDefault)
 		return zero, nil
 	}
 
-	v, err := lv.Load(func() (V, error) { return fetch(id) })
+	if args.fetchPrev != nil {
+		fetch = func(k K) (V, error) {
+			var pv V
+			var hadPrev bool
+			if prior != nil {
+				var err error
+				pv, hadPrev, err = prior.Value()
+				hadPrev = hadPrev && err == nil
+			}
+			return args.fetchPrev(k, pv, hadPrev)
+		}
+	}
+	if args.merge != nil && prior != nil {
+		fetch = args.merging(fetch, prior)
+	}
+	load := args.loader(id, fetch)
+	args.from(SourceFetch)
+	var err error
+	if reloadInPlace {
+		var displaced *result[V]
+		v, displaced, err = lv.reload(load, args.equal, args.keepOnError)
+		args.closeDisplaced(id, displaced, v)
+	} else if args.retryOnError {
+		v, err = lv.LoadRetryable(load)
+	} else if waitCtx, cancelFetch, stop, ok := args.waitContext(); ok {
+		v, err = lv.loadShared(waitCtx, cancelFetch, args.lingers(), load)
+		stop()
+		if err != nil && err == waitCtx.Err() {
+			// The caller gave up waiting; the fetch may still complete for others.
+			if args.waitCtx != nil && args.waitCtx.Err() != nil {
+				return zero, args.waitCtx.Err()
+			}
+			return zero, ErrLoadTimeout
+		}
+	} else {
+		v, err = lv.Load(load)
+	}
+	if err != nil && previous != nil {
+		// The refresh failed; keep serving the value it was meant to replace.
+		pv, _, _ := previous.Value()
+		args.from(SourceCache)
+		return pv, nil
+	}
+	if previous != nil {
+		swapIn(m, mu, id, previous, lv, args)
+	}
 	if err != nil {
-		if args.defaultValue != nil && !args.must {
-			lv.Store(*args.defaultValue)
+		if dv, ok := args.fetchDefault(id, err); ok {
+			args.from(SourceDefault)
+			// Caching the default would make the failure final, defeating WithRetryOnError.
+			if !args.retryOnError {
+				lv.Store(dv)
+			}
 			// Should we consider default value access? Yes.
 			if args.evictionPolicy != nil {
 				args.evictionPolicy.Access(id)
 			}
-			return *args.defaultValue, nil
+			return dv, nil
 		}
 		if args.must {
-			return v, fmt.Errorf("fetch error: %w", err)
+			return v, FetchError[K]{Key: id, Err: err}
 		}
 		return v, err
 	}
@@ -352,20 +1294,50 @@ type LazyMap[K comparable, V any] struct {
 	mu   sync.RWMutex
 	m    map[K]*Value[V]
 	opts []Option[K, V]
+	// defaults is opts already applied, used when a call adds no options of its own
+	// so that cache hits don't allocate.
+	defaults *args[K, V]
 }
 
 // NewLazyMap creates a new LazyMap with optional default settings.
 func NewLazyMap[K comparable, V any](opts ...Option[K, V]) *LazyMap[K, V] {
+	opts = append(opts[:len(opts):len(opts)],
+		withStats[K, V](&statsCounters{}),
+		withEvents(&eventHub[K, V]{}),
+		withGeneration[K, V](new(atomic.Uint32)),
+	)
+	defaults := buildArgs(opts)
+	if defaults.copyOnWrite {
+		opts = append(opts, withCOW(&cowMap[K, V]{}))
+		defaults = buildArgs(opts)
+	}
 	return &LazyMap[K, V]{
-		m:    make(map[K]*Value[V]),
-		opts: opts,
+		m:        make(map[K]*Value[V], defaults.initialCapacity),
+		opts:     opts,
+		defaults: defaults,
 	}
 }
 
+// config returns the map's default configuration.
+func (lm *LazyMap[K, V]) config() *args[K, V] {
+	if lm.defaults == nil {
+		return &args[K, V]{}
+	}
+	return lm.defaults
+}
+
 // Get retrieves or creates a value for the given key.
 // It wraps the Map function, handling the map and mutex automatically.
 // Options passed here are merged with the default options provided to NewLazyMap.
 func (lm *LazyMap[K, V]) Get(key K, fetch func(K) (V, error), opts ...Option[K, V]) (V, error) {
+	if len(opts) == 0 && lm.defaults != nil {
+		if lm.defaults.cow != nil {
+			if v, ok := lm.lookupCOW(key, fetch, lm.defaults); ok {
+				return v, nil
+			}
+		}
+		return mapWith(&lm.m, &lm.mu, key, fetch, lm.defaults)
+	}
 	// Combine default options with call-specific options.
 	// Call-specific options come last to override defaults.
 	combinedOpts := make([]Option[K, V], 0, len(lm.opts)+len(opts))
@@ -374,6 +1346,67 @@ func (lm *LazyMap[K, V]) Get(key K, fetch func(K) (V, error), opts ...Option[K,
 	return Map(&lm.m, &lm.mu, key, fetch, combinedOpts...)
 }
 
+// GetIfPresent returns the cached value for key and true, or the zero value and false if the key
+// is absent, not loaded, loaded with an error, or expired. It never fetches, never adds an entry,
+// and doesn't count as a use.
+func (lm *LazyMap[K, V]) GetIfPresent(key K) (V, bool) {
+	var zero V
+	cfg := lm.config()
+	lm.mu.RLock()
+	lv, ok := lm.m[key]
+	lm.mu.RUnlock()
+	if !ok {
+		return zero, false
+	}
+	v, loaded, err := lv.Value()
+	if !loaded || err != nil || cfg.isExpired(lv) {
+		return zero, false
+	}
+	return v, true
+}
+
+// Peek returns the cached value for key, or ErrValueNotCached if the key is absent, not loaded
+// or expired. If the value was loaded with an error, that error is returned. Like GetIfPresent it
+// never fetches, never adds an entry and doesn't count as a use; it is shorthand for the common
+// Get(key, nil, DontFetch(), MustBeCached()) lookup without those side effects.
+func (lm *LazyMap[K, V]) Peek(key K) (V, error) {
+	var zero V
+	cfg := lm.config()
+	lm.mu.RLock()
+	lv, ok := lm.m[key]
+	lm.mu.RUnlock()
+	if !ok {
+		return zero, ErrValueNotCached
+	}
+	v, loaded, err := lv.Value()
+	if !loaded || cfg.isExpired(lv) {
+		return zero, ErrValueNotCached
+	}
+	if err != nil {
+		return zero, err
+	}
+	return v, nil
+}
+
+// Touch records an access to key without reading its value: the entry's Uses count goes up,
+// its LastAccess is updated and the eviction policy is told about it, just as for a cache hit.
+// This keeps an entry alive under ExpireAfterIdle, and counts towards ExpireAfterUses.
+// It returns false, doing nothing, if the key is absent, not loaded or already expired.
+func (lm *LazyMap[K, V]) Touch(key K) bool {
+	cfg := lm.config()
+	lm.mu.RLock()
+	lv, ok := lm.m[key]
+	lm.mu.RUnlock()
+	if !ok || !lv.IsLoaded() || cfg.isExpired(lv) {
+		return false
+	}
+	lv.used()
+	if cfg.evictionPolicy != nil {
+		cfg.evictionPolicy.Access(key)
+	}
+	return true
+}
+
 // Set manually sets the value for the given key.
 func (lm *LazyMap[K, V]) Set(key K, value V) {
 	// We use Map with Set option. We also pass global options so policies (like eviction) are respected if Access is triggered.
@@ -384,10 +1417,84 @@ func (lm *LazyMap[K, V]) Set(key K, value V) {
 	_, _ = Map(&lm.m, &lm.mu, key, nil, combinedOpts...)
 }
 
-// Remove removes the value associated with the key.
-func (lm *LazyMap[K, V]) Remove(key K) {
-	combinedOpts := make([]Option[K, V], 0, len(lm.opts)+1)
+// Put sets the value for the given key, replacing it even if it is already loaded.
+// Unlike Set, which leaves a loaded value alone, Put always stores value; see Value.Overwrite.
+func (lm *LazyMap[K, V]) Put(key K, value V) {
+	var loaded bool
+	var lv *Value[V]
+	combinedOpts := make([]Option[K, V], 0, len(lm.opts)+3)
 	combinedOpts = append(combinedOpts, lm.opts...)
-	combinedOpts = append(combinedOpts, Clear[K, V]())
+	combinedOpts = append(combinedOpts, Set[K, V](value), setLoadedDest[K, V](&loaded), WithValueDest[K, V](&lv))
 	_, _ = Map(&lm.m, &lm.mu, key, nil, combinedOpts...)
+	if loaded {
+		a := lm.config()
+		a.closeDisplaced(key, lv.overwrite(value), value)
+		a.publish(EventSet, key, value)
+	}
+}
+
+// SetMany stores every key/value pair in values under a single acquisition of the map lock,
+// replacing existing entries as Put does. It is intended for priming the cache after a batch
+// query. MaxSize is respected, evicting entries as needed while inserting; the eviction
+// policy sees each key as it is stored, so with LRU the entries stored last are kept.
+func (lm *LazyMap[K, V]) SetMany(values map[K]V) {
+	lm.storeAll(values)
+}
+
+// GetOrSet returns the existing value for the key if one is loaded, with loaded set to true.
+// Otherwise it stores value and returns it with loaded set to false.
+// Like sync.Map.LoadOrStore, the check and store happen atomically: when several callers race
+// on the same key exactly one of them stores its value.
+func (lm *LazyMap[K, V]) GetOrSet(key K, value V) (actual V, loaded bool) {
+	combinedOpts := make([]Option[K, V], 0, len(lm.opts)+2)
+	combinedOpts = append(combinedOpts, lm.opts...)
+	combinedOpts = append(combinedOpts, Set[K, V](value), setLoadedDest[K, V](&loaded))
+	actual, _ = Map(&lm.m, &lm.mu, key, nil, combinedOpts...)
+	return actual, loaded
+}
+
+// LoadOr stores value as the cached entry for key unless one is already loaded, and returns the
+// entry now held, with stored reporting whether it is value. It is the primitive for pushed
+// updates, e.g. from a webhook, that shouldn't displace a value already cached: unlike Set it
+// reports the outcome, and unlike Put it never replaces a loaded value. It is GetOrSet with the
+// result the other way round, and likewise tells the eviction policy about the access.
+func (lm *LazyMap[K, V]) LoadOr(key K, value V) (actual V, stored bool) {
+	actual, loaded := lm.GetOrSet(key, value)
+	return actual, !loaded
+}
+
+// setLoadedDest returns an Option that makes a Set report whether the key was already loaded.
+// When present, Map returns the value actually held rather than the value passed to Set.
+func setLoadedDest[K comparable, V any](loaded *bool) Option[K, V] {
+	return func(a *args[K, V]) { a.setLoaded = loaded }
+}
+
+// Delete removes the value associated with the key, reporting whether the key was present.
+// The eviction policy is told about the removal, and the WithEvictionCallback callback, if any,
+// is called with the deleted value once the map lock has been released.
+func (lm *LazyMap[K, V]) Delete(key K) bool {
+	a := lm.config()
+	lm.mu.Lock()
+	lv, ok := lm.m[key]
+	if ok {
+		delete(lm.m, key)
+		a.republish(lm.m)
+	}
+	lm.mu.Unlock()
+	if !ok {
+		return false
+	}
+	// The callback runs first so that it sees the value before WithAutoClose closes it.
+	if a.onEvict != nil {
+		if v, ok, err := lv.Value(); ok && err == nil {
+			a.onEvict(key, v)
+		}
+	}
+	a.removed([]removal[K, V]{{key: key, value: lv, reason: RemovalCleared}})
+	return true
+}
+
+// Remove removes the value associated with the key. It is Delete without the result.
+func (lm *LazyMap[K, V]) Remove(key K) {
+	lm.Delete(key)
 }
@@ -2,8 +2,11 @@ package lazy_test
 
 import (
 	"errors"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	lazy "github.com/arran4/go-be-lazy"
 )
@@ -34,3 +37,232 @@ func TestMapErrorsIs(t *testing.T) {
 		}
 	})
 }
+
+func TestValueLoadRetryable(t *testing.T) {
+	var v lazy.Value[int]
+	calls := 0
+	fetch := func() (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errors.New("transient")
+		}
+		return calls, nil
+	}
+	if _, err := v.LoadRetryable(fetch); err == nil {
+		t.Fatal("expected first attempt to fail")
+	}
+	if got, err := v.LoadRetryable(fetch); err != nil || got != 2 {
+		t.Fatalf("retry got %v %v", got, err)
+	}
+	if got, err := v.LoadRetryable(fetch); err != nil || got != 2 {
+		t.Fatalf("cached got %v %v", got, err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls=%d", calls)
+	}
+}
+
+func TestValueLoadRetryableSharesFailedAttempt(t *testing.T) {
+	var v lazy.Value[int]
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = v.LoadRetryable(func() (int, error) {
+			calls.Add(1)
+			close(started)
+			<-release
+			return 0, errors.New("fail")
+		})
+	}()
+	<-started
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = v.LoadRetryable(func() (int, error) {
+				calls.Add(1)
+				return 1, nil
+			})
+		}()
+	}
+	// Give the waiters time to queue up behind the in-flight attempt.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected waiters to share the failed attempt, fetch ran %d times", n)
+	}
+}
+
+func TestMapRetryOnError(t *testing.T) {
+	fail := errors.New("transient")
+	t.Run("Plain", func(t *testing.T) {
+		m := make(map[int32]*lazy.Value[int])
+		var mu sync.RWMutex
+		calls := 0
+		fetch := func(int32) (int, error) {
+			calls++
+			if calls == 1 {
+				return 0, fail
+			}
+			return 7, nil
+		}
+		retry := lazy.WithRetryOnError[int32, int]()
+		if _, err := lazy.Map(&m, &mu, 1, fetch, retry); !errors.Is(err, fail) {
+			t.Fatalf("err=%v", err)
+		}
+		if v, err := lazy.Map(&m, &mu, 1, fetch, retry); err != nil || v != 7 {
+			t.Fatalf("retry got %v %v", v, err)
+		}
+		if v, err := lazy.Map(&m, &mu, 1, fetch, retry); err != nil || v != 7 || calls != 2 {
+			t.Fatalf("cached got %v %v calls=%d", v, err, calls)
+		}
+	})
+	t.Run("DefaultValue", func(t *testing.T) {
+		m := make(map[int32]*lazy.Value[int])
+		var mu sync.RWMutex
+		opts := []lazy.Option[int32, int]{lazy.WithRetryOnError[int32, int](), lazy.DefaultValue[int32, int](-1)}
+		if v, err := lazy.Map(&m, &mu, 1, func(int32) (int, error) { return 0, fail }, opts...); err != nil || v != -1 {
+			t.Fatalf("got %v %v", v, err)
+		}
+		if v, err := lazy.Map(&m, &mu, 1, func(int32) (int, error) { return 3, nil }, opts...); err != nil || v != 3 {
+			t.Fatalf("expected the default not to be cached, got %v %v", v, err)
+		}
+	})
+	t.Run("Must", func(t *testing.T) {
+		m := make(map[int32]*lazy.Value[int])
+		var mu sync.RWMutex
+		opts := []lazy.Option[int32, int]{lazy.WithRetryOnError[int32, int](), lazy.Must[int32, int]()}
+		if _, err := lazy.Map(&m, &mu, 1, func(int32) (int, error) { return 0, fail }, opts...); !errors.Is(err, fail) {
+			t.Fatalf("err=%v", err)
+		}
+		if v, err := lazy.Map(&m, &mu, 1, func(int32) (int, error) { return 3, nil }, opts...); err != nil || v != 3 {
+			t.Fatalf("got %v %v", v, err)
+		}
+	})
+}
+
+func TestMapWithDefaultFunc(t *testing.T) {
+	errNotFound := errors.New("not found")
+	errTimeout := errors.New("timeout")
+	defaults := lazy.WithDefaultFunc[string, []string](func(_ string, err error) ([]string, bool) {
+		if errors.Is(err, errNotFound) {
+			return []string{}, true
+		}
+		return nil, false
+	})
+	lm := lazy.NewLazyMap[string, []string](defaults)
+
+	v, err := lm.Get("missing", func(string) ([]string, error) { return nil, errNotFound })
+	if err != nil || v == nil || len(v) != 0 {
+		t.Fatalf("not found got %v %v", v, err)
+	}
+	if cached, ok := lm.GetIfPresent("missing"); !ok || cached == nil {
+		t.Fatalf("default not cached: %v %v", cached, ok)
+	}
+
+	if _, err := lm.Get("slow", func(string) ([]string, error) { return nil, errTimeout }); !errors.Is(err, errTimeout) {
+		t.Fatalf("timeout err=%v", err)
+	}
+
+	_, err = lm.Get("must", func(string) ([]string, error) { return nil, errNotFound }, lazy.Must[string, []string]())
+	if !errors.Is(err, errNotFound) {
+		t.Fatalf("Must err=%v", err)
+	}
+}
+
+func TestMapWithRecover(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](lazy.WithRecover[string, int]())
+	_, err := lm.Get("boom", func(string) (int, error) { panic("kaboom") })
+	if !errors.Is(err, lazy.ErrFetchPanic) {
+		t.Fatalf("err=%v", err)
+	}
+	var pe *lazy.PanicError
+	if !errors.As(err, &pe) || pe.Value != "kaboom" || len(pe.Stack) == 0 {
+		t.Fatalf("PanicError=%+v", pe)
+	}
+	if !strings.Contains(err.Error(), "kaboom") || !strings.Contains(err.Error(), "goroutine") {
+		t.Fatalf("error string lacks panic value or stack: %q", err.Error())
+	}
+
+	// The map is still usable afterwards.
+	if v, err := lm.Get("ok", func(string) (int, error) { return 1, nil }); err != nil || v != 1 {
+		t.Fatalf("got %v %v", v, err)
+	}
+	if v, err := lm.Get("boom", func(string) (int, error) { return 2, nil }, lazy.Refresh[string, int]()); err != nil || v != 2 {
+		t.Fatalf("refresh after panic got %v %v", v, err)
+	}
+}
+
+func TestLazyMapErrors(t *testing.T) {
+	lm := lazy.NewLazyMap[int, int]()
+	bad := errors.New("bad")
+	fetch := func(k int) (int, error) {
+		if k%2 == 1 {
+			return 0, bad
+		}
+		return k, nil
+	}
+	for i := 0; i < 6; i++ {
+		_, _ = lm.Get(i, fetch)
+	}
+
+	errs := lm.Errors()
+	if len(errs) != 3 {
+		t.Fatalf("Errors=%v", errs)
+	}
+	for k, err := range errs {
+		if k%2 != 1 || !errors.Is(err, bad) {
+			t.Fatalf("Errors[%d]=%v", k, err)
+		}
+	}
+
+	var lv *lazy.Value[int]
+	_, _ = lm.Get(1, nil, lazy.DontFetch[int, int](), lazy.WithValueDest[int, int](&lv))
+	uses := lv.Uses()
+	if !lv.HasError() || !errors.Is(lv.Err(), bad) || lv.Uses() != uses {
+		t.Fatalf("HasError=%v Err=%v", lv.HasError(), lv.Err())
+	}
+	_, _ = lm.Get(0, nil, lazy.DontFetch[int, int](), lazy.WithValueDest[int, int](&lv))
+	if lv.HasError() || lv.Err() != nil {
+		t.Fatalf("good value HasError=%v Err=%v", lv.HasError(), lv.Err())
+	}
+}
+
+func TestRecursiveLoad(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](lazy.WithRecursionGuard[string, int]())
+	var inner error
+	var fetch func(string) (int, error)
+	fetch = func(k string) (int, error) {
+		if k == "b" {
+			return 2, nil
+		}
+		// Loading a different key from a fetch is fine.
+		b, err := lm.Get("b", fetch)
+		if err != nil {
+			return 0, err
+		}
+		// Loading the same key would deadlock, so it fails instead.
+		_, inner = lm.Get(k, fetch)
+		return b + 1, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if v, err := lm.Get("a", fetch); err != nil || v != 3 {
+			t.Errorf("Get(a) = %v, %v", v, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("recursive load deadlocked")
+	}
+	if !errors.Is(inner, lazy.ErrRecursiveLoad) {
+		t.Fatalf("inner error = %v, want ErrRecursiveLoad", inner)
+	}
+}
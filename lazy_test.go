@@ -1,10 +1,16 @@
 package lazy_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	lazy "github.com/arran4/go-be-lazy"
 )
@@ -49,6 +55,70 @@ func TestValueLoadError(t *testing.T) {
 	}
 }
 
+func TestValueLoadWithTimeout(t *testing.T) {
+	var v lazy.Value[int]
+	release := make(chan struct{})
+	calls := 0
+	_, err := v.LoadWithTimeout(10*time.Millisecond, func() (int, error) {
+		calls++
+		<-release
+		return 7, nil
+	})
+	if !errors.Is(err, lazy.ErrLoadTimeout) {
+		t.Fatalf("err=%v", err)
+	}
+	close(release)
+	got, err := v.LoadWithTimeout(time.Second, func() (int, error) {
+		calls++
+		return 99, nil
+	})
+	if err != nil || got != 7 {
+		t.Fatalf("late result got %v %v", got, err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls=%d", calls)
+	}
+}
+
+func TestValueWait(t *testing.T) {
+	var v lazy.Value[int]
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = v.Load(func() (int, error) {
+			close(started)
+			<-release
+			return 11, nil
+		})
+	}()
+	<-started
+
+	got := make(chan int)
+	go func() {
+		n, err := v.Wait(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		got <- n
+	}()
+	select {
+	case n := <-got:
+		t.Fatalf("Wait returned %d before the load finished", n)
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	if n := <-got; n != 11 {
+		t.Fatalf("Wait got %d", n)
+	}
+
+	var empty lazy.Value[int]
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := empty.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
 func TestValueSetPeek(t *testing.T) {
 	var v lazy.Value[string]
 	v.Set("hello")
@@ -61,6 +131,61 @@ func TestValueSetPeek(t *testing.T) {
 	}
 }
 
+func TestValueCompareAndRefresh(t *testing.T) {
+	eq := func(a, b int) bool { return a == b }
+	var v lazy.Value[int]
+	if v.CompareAndRefresh(0, eq, 1) {
+		t.Fatal("CompareAndRefresh succeeded on an unloaded value")
+	}
+
+	v.Set(1)
+	old, _ := v.Peek()
+	if !v.CompareAndRefresh(old, eq, old+1) {
+		t.Fatal("CompareAndRefresh failed although the value was unchanged")
+	}
+	if got, _ := v.Peek(); got != 2 {
+		t.Fatalf("Expected 2, got %d", got)
+	}
+
+	// The value changes between the read and the write, so the write is rejected.
+	old, _ = v.Peek()
+	v.Overwrite(10)
+	if v.CompareAndRefresh(old, eq, old+1) {
+		t.Fatal("CompareAndRefresh succeeded although the value had changed")
+	}
+	if got, _ := v.Peek(); got != 10 {
+		t.Fatalf("Expected 10, got %d", got)
+	}
+}
+
+func TestValueSetError(t *testing.T) {
+	errOpen := errors.New("circuit open")
+	var v lazy.Value[int]
+	v.Set(1)
+	v.SetError(errOpen)
+
+	if _, ok, err := v.Value(); !ok || !errors.Is(err, errOpen) {
+		t.Fatalf("Expected the injected error, got %v %v", ok, err)
+	}
+	if !v.HasError() || v.Err() != errOpen {
+		t.Fatalf("Expected HasError and Err to report the injected error, got %v", v.Err())
+	}
+	if _, err := v.Load(func() (int, error) { return 2, nil }); err != errOpen {
+		t.Fatalf("Expected Load to short-circuit with the injected error, got %v", err)
+	}
+
+	// WithRetryOnError fetches again, clearing the injected error.
+	m := map[string]*lazy.Value[int]{"k": &v}
+	var mu sync.RWMutex
+	got, err := lazy.Map(&m, &mu, "k", func(string) (int, error) { return 3, nil }, lazy.WithRetryOnError[string, int]())
+	if err != nil || got != 3 {
+		t.Fatalf("Expected the retry to load 3, got %v %v", got, err)
+	}
+	if v.HasError() {
+		t.Fatal("Expected the error to be cleared")
+	}
+}
+
 func TestMapNilMap(t *testing.T) {
 	var mu sync.RWMutex
 	_, err := lazy.Map[int, int](nil, &mu, 1, nil)
@@ -111,10 +236,18 @@ func TestMapDontFetchDefaultValue(t *testing.T) {
 func TestMapMustWrapError(t *testing.T) {
 	m := make(map[int32]*lazy.Value[int])
 	var mu sync.RWMutex
-	_, err := lazy.Map(&m, &mu, 1, func(int32) (int, error) { return 0, errors.New("bad") }, lazy.Must[int32, int]())
-	if err == nil || err.Error() != "fetch error: bad" {
+	bad := errors.New("bad")
+	_, err := lazy.Map(&m, &mu, 1, func(int32) (int, error) { return 0, bad }, lazy.Must[int32, int]())
+	if err == nil || err.Error() != "fetch error for key 1: bad" {
 		t.Fatalf("err=%v", err)
 	}
+	if !errors.Is(err, bad) {
+		t.Fatalf("errors.Is failed for %v", err)
+	}
+	var fe lazy.FetchError[int32]
+	if !errors.As(err, &fe) || fe.Key != 1 || fe.Err != bad {
+		t.Fatalf("errors.As failed for %v: %+v", err, fe)
+	}
 }
 
 func TestMapDefaultValueOnError(t *testing.T) {
@@ -413,3 +546,468 @@ func TestLazyMap(t *testing.T) {
 		t.Fatalf("Remove failed: %v %v", v, err)
 	}
 }
+
+func TestMapRefreshWithEqual(t *testing.T) {
+	m := make(map[int32]*lazy.Value[int])
+	var mu sync.RWMutex
+	calls := 0
+	value := 1
+	fetch := func(int32) (int, error) { calls++; return value, nil }
+	eq := lazy.WithEqual[int32, int](func(a, b int) bool { return a == b })
+
+	Must(lazy.Map(&m, &mu, 1, fetch, eq))
+	first := m[1]
+	createdAt := first.CreatedAt()
+
+	if v := Must(lazy.Map(&m, &mu, 1, fetch, eq, lazy.Refresh[int32, int]())); v != 1 {
+		t.Fatalf("refresh got %d", v)
+	}
+	if m[1] != first {
+		t.Fatal("expected refresh to reuse the existing value")
+	}
+	if !m[1].CreatedAt().Equal(createdAt) {
+		t.Fatal("expected equal refresh to keep the existing result")
+	}
+
+	value = 2
+	if v := Must(lazy.Map(&m, &mu, 1, fetch, eq, lazy.Refresh[int32, int]())); v != 2 {
+		t.Fatalf("refresh got %d", v)
+	}
+	if v := Must(lazy.Map(&m, &mu, 1, fetch, eq)); v != 2 {
+		t.Fatalf("cached got %d", v)
+	}
+	if calls != 3 {
+		t.Fatalf("calls=%d", calls)
+	}
+}
+
+func BenchmarkMapRefreshIdentical(b *testing.B) {
+	fetch := func(int32) (int, error) { return 42, nil }
+	run := func(b *testing.B, opts ...lazy.Option[int32, int]) {
+		m := make(map[int32]*lazy.Value[int])
+		var mu sync.RWMutex
+		Must(lazy.Map(&m, &mu, 1, fetch, opts...))
+		opts = append(opts, lazy.Refresh[int32, int]())
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			Must(lazy.Map(&m, &mu, 1, fetch, opts...))
+		}
+	}
+	b.Run("Replace", func(b *testing.B) { run(b) })
+	b.Run("WithEqual", func(b *testing.B) {
+		run(b, lazy.WithEqual[int32, int](func(a, b int) bool { return a == b }))
+	})
+}
+
+func TestLazyMapGetIfPresent(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](lazy.WithExpiry[string, int](lazy.ExpireAfterUses[int](3)))
+	fetch := func(string) (int, error) { return 1, nil }
+
+	if _, ok := lm.GetIfPresent("a"); ok {
+		t.Fatal("absent key reported present")
+	}
+	if lm.Len() != 0 {
+		t.Fatal("GetIfPresent added an entry")
+	}
+
+	Must(lm.Get("a", fetch))
+	for i := 0; i < 5; i++ {
+		// Doesn't count as a use, so it never pushes the entry into expiry.
+		if v, ok := lm.GetIfPresent("a"); !ok || v != 1 {
+			t.Fatalf("present key got %v %v", v, ok)
+		}
+	}
+
+	Must(lm.Get("a", fetch))
+	Must(lm.Get("a", fetch))
+	if _, ok := lm.GetIfPresent("a"); ok {
+		t.Fatal("expired key reported present")
+	}
+}
+
+func TestLazyMapPeek(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](lazy.WithExpiry[string, int](lazy.ExpireAfterUses[int](2)))
+	fetch := func(string) (int, error) { return 1, nil }
+
+	if _, err := lm.Peek("a"); !errors.Is(err, lazy.ErrValueNotCached) {
+		t.Fatalf("absent err=%v", err)
+	}
+	Must(lm.Get("a", fetch))
+	for i := 0; i < 3; i++ {
+		if v, err := lm.Peek("a"); err != nil || v != 1 {
+			t.Fatalf("present got %v %v", v, err)
+		}
+	}
+	Must(lm.Get("a", fetch))
+	if _, err := lm.Peek("a"); !errors.Is(err, lazy.ErrValueNotCached) {
+		t.Fatalf("expired err=%v", err)
+	}
+
+	bad := errors.New("bad")
+	_, _ = lm.Get("b", func(string) (int, error) { return 0, bad })
+	if _, err := lm.Peek("b"); !errors.Is(err, bad) {
+		t.Fatalf("cached error err=%v", err)
+	}
+}
+
+func TestLazyMapPut(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	fetch := func(string) (int, error) { return 1, nil }
+	Must(lm.Get("a", fetch))
+
+	lm.Set("a", 2)
+	if v := Must(lm.Get("a", fetch)); v != 1 {
+		t.Fatalf("Set replaced a loaded value: %d", v)
+	}
+	lm.Put("a", 3)
+	if v := Must(lm.Get("a", fetch)); v != 3 {
+		t.Fatalf("Put didn't replace the loaded value: %d", v)
+	}
+	lm.Put("b", 4)
+	if v := Must(lm.Get("b", fetch)); v != 4 {
+		t.Fatalf("Put on a missing key got %d", v)
+	}
+}
+
+func TestLazyMapSetMany(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](
+		lazy.MaxSize[string, int](3),
+		lazy.WithEvictionPolicy[string, int](lazy.NewLRUEvictionPolicy[string, int]()),
+	)
+	lm.Set("old", 0)
+	lm.Set("a", 0)
+
+	batch := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}
+	lm.SetMany(batch)
+
+	if lm.Len() != 3 {
+		t.Fatalf("Len=%d", lm.Len())
+	}
+	if _, ok := lm.GetIfPresent("old"); ok {
+		t.Fatal("expected the entry older than the batch to be evicted first")
+	}
+	for _, k := range lm.Keys() {
+		if v, _ := lm.GetIfPresent(k); v != batch[k] {
+			t.Fatalf("%s=%d, want %d", k, v, batch[k])
+		}
+	}
+}
+
+func TestLazyMapSetManyInPlace(t *testing.T) {
+	lm := lazy.NewLazyMap[string, *fakeCloser](lazy.WithAutoClose[string, *fakeCloser]())
+	var before, after *lazy.Value[*fakeCloser]
+	c := Must(lm.Get("a", func(k string) (*fakeCloser, error) { return &fakeCloser{name: k}, nil },
+		lazy.WithValueDest[string, *fakeCloser](&before)))
+
+	// Storing the value already cached must neither swap the entry out nor close it.
+	lm.SetMany(map[string]*fakeCloser{"a": c})
+	Must(lm.Get("a", nil, lazy.WithValueDest[string, *fakeCloser](&after)))
+	if after != before {
+		t.Fatal("SetMany replaced the existing entry instead of updating it like Put")
+	}
+	if n := c.closed.Load(); n != 0 {
+		t.Fatalf("SetMany closed the value it stored %d times", n)
+	}
+}
+
+func TestLazyMapGetOrSet(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	if v, loaded := lm.GetOrSet("a", 1); loaded || v != 1 {
+		t.Fatalf("first GetOrSet got %v %v", v, loaded)
+	}
+	if v, loaded := lm.GetOrSet("a", 2); !loaded || v != 1 {
+		t.Fatalf("second GetOrSet got %v %v", v, loaded)
+	}
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint("race", i)
+		var wg sync.WaitGroup
+		results := make([]bool, 2)
+		values := make([]int, 2)
+		for g := 0; g < 2; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				values[g], results[g] = lm.GetOrSet(key, g)
+			}(g)
+		}
+		wg.Wait()
+		if results[0] == results[1] {
+			t.Fatalf("%s: expected exactly one store, got loaded=%v", key, results)
+		}
+		if values[0] != values[1] {
+			t.Fatalf("%s: callers saw different values %v", key, values)
+		}
+	}
+}
+
+func TestLazyMapLoadOr(t *testing.T) {
+	policy := lazy.NewLRUEvictionPolicy[string, int]()
+	lm := lazy.NewLazyMap[string, int](lazy.WithEvictionPolicy[string, int](policy))
+
+	if v, stored := lm.LoadOr("a", 1); !stored || v != 1 {
+		t.Fatalf("Expected the pushed value to be stored, got %v %v", v, stored)
+	}
+	Must(lm.Get("b", func(string) (int, error) { return 2, nil }))
+	if v, stored := lm.LoadOr("b", 3); stored || v != 2 {
+		t.Fatalf("Expected the cached value to be kept, got %v %v", v, stored)
+	}
+	// Both calls counted as accesses, so b is the most recently used.
+	if order := policy.Order(); !slices.Equal(order, []string{"b", "a"}) {
+		t.Fatalf("Expected LRU order [b a], got %v", order)
+	}
+}
+
+func TestMapWithValueDest(t *testing.T) {
+	m := make(map[int32]*lazy.Value[int])
+	var mu sync.RWMutex
+	fetch := func(id int32) (int, error) { return int(id), nil }
+
+	var lv *lazy.Value[int]
+	Must(lazy.Map(&m, &mu, 1, fetch, lazy.WithValueDest[int32, int](&lv)))
+	if lv == nil || lv != m[1] {
+		t.Fatal("expected the map's value pointer")
+	}
+	if lv.Uses() != 1 {
+		t.Fatalf("uses=%d", lv.Uses())
+	}
+	Must(lazy.Map(&m, &mu, 1, fetch))
+	if lv.Uses() != 2 {
+		t.Fatalf("uses after cache hit=%d", lv.Uses())
+	}
+}
+
+func TestLazyMapGetEntry(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	fetch := func(string) (int, error) { return 5, nil }
+
+	before := time.Now()
+	Must(lm.Get("a", fetch))
+	after := time.Now()
+	Must(lm.Get("a", fetch))
+
+	e, err := lm.GetEntry("a", fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Value != 5 || !e.Loaded || e.Err != nil {
+		t.Fatalf("entry=%+v", e)
+	}
+	if e.Uses != 3 {
+		t.Fatalf("Uses=%d", e.Uses)
+	}
+	if e.CreatedAt.Before(before) || e.CreatedAt.After(after) {
+		t.Fatalf("CreatedAt=%v not in [%v, %v]", e.CreatedAt, before, after)
+	}
+
+	e, err = lm.GetEntry("missing", nil, lazy.DontFetch[string, int]())
+	if err != nil || e.Loaded || !e.CreatedAt.IsZero() {
+		t.Fatalf("missing entry=%+v err=%v", e, err)
+	}
+}
+
+func TestLazyMapGetNoErr(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](lazy.DefaultValue[string, int](-1))
+	calls := 0
+	square := func(k string) int { calls++; return len(k) * len(k) }
+
+	for i := 0; i < 3; i++ {
+		if v := lm.GetNoErr("abc", square); v != 9 {
+			t.Fatalf("got %d", v)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("calls=%d", calls)
+	}
+	if v, err := lm.Get("abcd", lazy.MustFetch(square)); err != nil || v != 16 || calls != 2 {
+		t.Fatalf("got %v %v calls=%d", v, err, calls)
+	}
+	// Defaults from NewLazyMap still apply.
+	if v, _ := lm.Get("missing", nil, lazy.DontFetch[string, int]()); v != -1 {
+		t.Fatalf("default got %d", v)
+	}
+}
+
+func TestWithoutUsageTracking(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](lazy.WithoutUsageTracking[string, int]())
+	var lv *lazy.Value[int]
+	fetch := func(string) (int, error) { return 1, nil }
+	for i := 0; i < 3; i++ {
+		Must(lm.Get("k", fetch, lazy.WithValueDest[string, int](&lv)))
+	}
+	if lv.Uses() != 0 {
+		t.Fatalf("uses = %d, want 0", lv.Uses())
+	}
+	if lv.LastAccess().IsZero() {
+		t.Fatal("LastAccess not recorded")
+	}
+
+	// Tracking is on by default.
+	tracked := lazy.NewLazyMap[string, int]()
+	for i := 0; i < 3; i++ {
+		Must(tracked.Get("k", fetch, lazy.WithValueDest[string, int](&lv)))
+	}
+	if lv.Uses() != 3 {
+		t.Fatalf("tracked uses = %d, want 3", lv.Uses())
+	}
+}
+
+func BenchmarkLazyMapGetParallel(b *testing.B) {
+	fetch := func(k int) (int, error) { return k, nil }
+	for _, bc := range []struct {
+		name string
+		opts []lazy.Option[int, int]
+	}{
+		{"Tracked", nil},
+		{"Untracked", []lazy.Option[int, int]{lazy.WithoutUsageTracking[int, int]()}},
+		{"CopyOnWrite", []lazy.Option[int, int]{lazy.WithCopyOnWrite[int, int]()}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			lm := lazy.NewLazyMap[int, int](bc.opts...)
+			Must(lm.Get(1, fetch))
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					Must(lm.Get(1, fetch))
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkLazyMapGet(b *testing.B) {
+	fetch := func(k int) (int, error) { return k, nil }
+	b.Run("Hit", func(b *testing.B) {
+		lm := lazy.NewLazyMap[int, int](lazy.MaxSize[int, int](10))
+		Must(lm.Get(1, fetch))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			Must(lm.Get(1, fetch))
+		}
+	})
+	b.Run("Refresh", func(b *testing.B) {
+		lm := lazy.NewLazyMap[int, int](lazy.MaxSize[int, int](10))
+		refresh := lazy.Refresh[int, int]()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Must(lm.Get(1, fetch, refresh))
+		}
+	})
+	b.Run("RefreshWithEqual", func(b *testing.B) {
+		lm := lazy.NewLazyMap[int, int](
+			lazy.MaxSize[int, int](10),
+			lazy.WithEqual[int, int](func(a, b int) bool { return a == b }),
+		)
+		refresh := lazy.Refresh[int, int]()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Must(lm.Get(1, fetch, refresh))
+		}
+	})
+}
+
+func TestMapPrev(t *testing.T) {
+	m := make(map[string]*lazy.Value[string])
+	var mu sync.RWMutex
+	type call struct {
+		prev    string
+		hadPrev bool
+	}
+	var calls []call
+	version := "v1"
+	fetch := func(k string, prev string, hadPrev bool) (string, error) {
+		calls = append(calls, call{prev, hadPrev})
+		// Like a conditional GET: keep the previous body if the version hasn't changed.
+		if hadPrev && strings.HasPrefix(prev, version+":") {
+			return prev, nil
+		}
+		return version + ":" + k, nil
+	}
+	refresh := lazy.Refresh[string, string]()
+
+	if v := Must(lazy.MapPrev(&m, &mu, "page", fetch)); v != "v1:page" {
+		t.Fatalf("first got %q", v)
+	}
+	if v := Must(lazy.MapPrev(&m, &mu, "page", fetch, refresh)); v != "v1:page" {
+		t.Fatalf("unchanged refresh got %q", v)
+	}
+	version = "v2"
+	if v := Must(lazy.MapPrev(&m, &mu, "page", fetch, refresh)); v != "v2:page" {
+		t.Fatalf("changed refresh got %q", v)
+	}
+	want := []call{{"", false}, {"v1:page", true}, {"v1:page", true}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+
+	// Expired entries pass their value on too.
+	calls = nil
+	Must(lazy.MapPrev(&m, &mu, "page", fetch, lazy.WithExpiry[string, string](lazy.ExpireAfterUses[string](1))))
+	if len(calls) != 1 || calls[0] != (call{"v2:page", true}) {
+		t.Fatalf("expiry calls = %v", calls)
+	}
+}
+
+func TestWithDefaultFetch(t *testing.T) {
+	var calls int
+	lm := lazy.NewLazyMap[string, int](lazy.WithDefaultFetch[string, int](func(k string) (int, error) {
+		calls++
+		return len(k), nil
+	}))
+
+	if v := Must(lm.Get("abc", nil)); v != 3 {
+		t.Fatalf("Expected the default fetch to load 3, got %d", v)
+	}
+	if v := Must(lm.Get("abc", nil)); v != 3 || calls != 1 {
+		t.Fatalf("Expected the cached value, got %d after %d calls", v, calls)
+	}
+	if v := Must(lm.Get("xy", func(string) (int, error) { return 100, nil })); v != 100 {
+		t.Fatalf("Expected the call's fetch to override the default, got %d", v)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected the default fetch to run once, ran %d times", calls)
+	}
+}
+
+// BenchmarkValueLoadCold measures a load that runs its function.
+func BenchmarkValueLoadCold(b *testing.B) {
+	fn := func() (int, error) { return 1, nil }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var v lazy.Value[int]
+		Must(v.Load(fn))
+	}
+}
+
+// BenchmarkLazyMapEntryFootprint reports the heap held per cached entry, including the map
+// bucket, the Value and its result.
+func BenchmarkLazyMapEntryFootprint(b *testing.B) {
+	const entries = 10_000
+	fetch := func(k int) (int, error) { return k, nil }
+	for _, bc := range []struct {
+		name string
+		opts []lazy.Option[int, int]
+	}{
+		{"OwnLocks", nil},
+		{"StripedLocks", []lazy.Option[int, int]{lazy.WithStripedLocks[int, int](64)}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var before, after runtime.MemStats
+			for i := 0; i < b.N; i++ {
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				lm := lazy.NewLazyMap[int, int](bc.opts...)
+				for k := 0; k < entries; k++ {
+					Must(lm.Get(k, fetch))
+				}
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc))/entries, "B/entry")
+				runtime.KeepAlive(lm)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package lazyhttp
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+// ExpireFromHTTPHeaders returns an Expiry policy that derives a cached response's freshness
+// lifetime from its Cache-Control and Expires headers, measured from the time it was cached by
+// the map's TimeSource.
+// Responses without explicit freshness information, marked no-store or no-cache, varying on *,
+// or with a status other than 200 OK are treated as immediately expired.
+func ExpireFromHTTPHeaders() lazy.Expiry[*Response] {
+	return &expireFromHTTPHeaders{}
+}
+
+type expireFromHTTPHeaders struct{}
+
+func (e *expireFromHTTPHeaders) IsExpired(v *lazy.Value[*Response]) bool {
+	r, loaded, err := v.Value()
+	if !loaded {
+		return false
+	}
+	if err != nil || r == nil {
+		return true
+	}
+	return v.Age() >= freshnessLifetime(r, v.CreatedAt())
+}
+
+// freshnessLifetime returns how long r, cached at created, may be served from cache.
+func freshnessLifetime(r *Response, created time.Time) time.Duration {
+	if r.StatusCode != http.StatusOK {
+		return 0
+	}
+	if varyAll(r.Header) {
+		return 0
+	}
+	for _, directive := range strings.Split(r.Header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0
+		case "max-age":
+			seconds, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+			if err != nil || seconds < 0 {
+				return 0
+			}
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	if expires := r.Header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		date, err := http.ParseTime(r.Header.Get("Date"))
+		if err != nil {
+			date = created
+		}
+		if lifetime := t.Sub(date); lifetime > 0 {
+			return lifetime
+		}
+	}
+	return 0
+}
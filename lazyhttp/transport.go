@@ -0,0 +1,216 @@
+// Package lazyhttp provides HTTP integrations built on the lazy package.
+// It is kept separate so the core package does not depend on net/http.
+package lazyhttp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+// Response is a cached HTTP response.
+type Response struct {
+	Status     string
+	StatusCode int
+	Proto      string
+	ProtoMajor int
+	ProtoMinor int
+	Header     http.Header
+	Body       []byte
+	// variant holds the request headers named by Vary, as sent when the response was fetched.
+	variant string
+}
+
+// CachingTransport is an http.RoundTripper that caches GET responses keyed by URL.
+// Requests carrying credentials (an Authorization or Cookie header) or a Range header, and
+// requests sent with Cache-Control: no-store, bypass the cache. A request sent with
+// Cache-Control: no-cache refetches the response and caches the new one. A response whose
+// Vary header names request headers is only served to requests whose values for them match
+// the request it was fetched for; others are passed to the inner transport.
+type CachingTransport struct {
+	inner http.RoundTripper
+	opts  []lazy.Option[string, *Response]
+	mu    sync.RWMutex
+	m     map[string]*lazy.Value[*Response]
+}
+
+// DefaultMaxEntries is how many responses a CachingTransport holds unless its options set
+// another MaxSize.
+const DefaultMaxEntries = 1000
+
+// NewCachingTransport returns a RoundTripper that serves GET responses from a cache while they
+// are fresh according to ExpireFromHTTPHeaders, calling inner.RoundTrip on a miss.
+// If inner is nil, http.DefaultTransport is used. The cache holds at most DefaultMaxEntries
+// responses, evicting the least recently used; bodies are held in full, so callers fetching large
+// responses should set a smaller MaxSize. The options are applied to the underlying map after
+// these defaults, so they may replace them, e.g. with MaxSize(0) for no bound.
+// A response that isn't fresh by its headers, and a failed round trip, is dropped from the cache
+// once it has been served, whatever Expiry is set, rather than kept until the URL is requested
+// again.
+func NewCachingTransport(inner http.RoundTripper, opts ...lazy.Option[string, *Response]) *CachingTransport {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	combinedOpts := make([]lazy.Option[string, *Response], 0, len(opts)+3)
+	combinedOpts = append(combinedOpts,
+		lazy.WithExpiry[string, *Response](ExpireFromHTTPHeaders()),
+		lazy.MaxSize[string, *Response](DefaultMaxEntries),
+		lazy.WithEvictionPolicy[string, *Response](lazy.NewLRUEvictionPolicy[string, *Response]()),
+	)
+	combinedOpts = append(combinedOpts, opts...)
+	return &CachingTransport{
+		inner: inner,
+		opts:  combinedOpts,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+// Requests other than GET are passed straight to the inner transport.
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !cacheable(req) {
+		return t.inner.RoundTrip(req)
+	}
+	opts := t.opts
+	if noCache(req.Header) {
+		opts = append(opts[:len(opts):len(opts)], lazy.Refresh[string, *Response]())
+	}
+	key := req.URL.String()
+	var fetched atomic.Bool
+	cached, err := lazy.MapContext(req.Context(), &t.m, &t.mu, key, func(ctx context.Context, _ string) (*Response, error) {
+		// ctx is shared by every request waiting on this fetch, and is only canceled once
+		// all of them have given up, so one client canceling doesn't fail the others.
+		fetched.Store(true)
+		return t.fetch(req.WithContext(ctx))
+	}, opts...)
+	if fetched.Load() {
+		t.dropUnfresh(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !fetched.Load() && !cached.matches(req) {
+		// The response varies on request headers that differ from those it was fetched with.
+		return t.inner.RoundTrip(req)
+	}
+	return cached.toHTTP(req), nil
+}
+
+// dropUnfresh removes the entry for key if it holds an error or a response that isn't fresh, so
+// that responses which can't be reused don't pile up, one per URL requested.
+func (t *CachingTransport) dropUnfresh(key string) {
+	t.mu.RLock()
+	lv := t.m[key]
+	t.mu.RUnlock()
+	if lv == nil {
+		return
+	}
+	if r, loaded, err := lv.Value(); !loaded || err == nil && freshnessLifetime(r, lv.CreatedAt()) > 0 {
+		return
+	}
+	_, _ = lazy.Map(&t.m, &t.mu, key, nil, append(t.opts[:len(t.opts):len(t.opts)], lazy.Clear[string, *Response]())...)
+}
+
+// cacheable reports whether req may be served from, and stored in, the cache.
+func cacheable(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != "" {
+		return false
+	}
+	for _, name := range []string{"Authorization", "Cookie", "Range"} {
+		if req.Header.Get(name) != "" {
+			return false
+		}
+	}
+	return !hasDirective(req.Header, "no-store")
+}
+
+// noCache reports whether the request asks for a response validated with the origin server.
+func noCache(h http.Header) bool {
+	if h.Get("Cache-Control") == "" {
+		return strings.EqualFold(strings.TrimSpace(h.Get("Pragma")), "no-cache")
+	}
+	return hasDirective(h, "no-cache")
+}
+
+// hasDirective reports whether h's Cache-Control header includes directive.
+func hasDirective(h http.Header, directive string) bool {
+	for _, d := range strings.Split(h.Get("Cache-Control"), ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if strings.EqualFold(name, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// variant returns the values h has for the headers named by a response's Vary header.
+func variant(vary []string, h http.Header) string {
+	var b strings.Builder
+	for _, v := range vary {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			b.WriteString(name)
+			b.WriteByte(':')
+			b.WriteString(strings.Join(h.Values(name), ","))
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// varyAll reports whether h has Vary: *, meaning no later request can be known to match.
+func varyAll(h http.Header) bool {
+	return slices.ContainsFunc(h.Values("Vary"), func(v string) bool { return strings.Contains(v, "*") })
+}
+
+// matches reports whether r may be served for req under r's Vary header.
+func (r *Response) matches(req *http.Request) bool {
+	return !varyAll(r.Header) && variant(r.Header.Values("Vary"), req.Header) == r.variant
+}
+
+// fetch performs the round trip and reads the body so it can be cached.
+func (t *CachingTransport) fetch(req *http.Request) (*Response, error) {
+	resp, err := t.inner.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Proto:      resp.Proto,
+		ProtoMajor: resp.ProtoMajor,
+		ProtoMinor: resp.ProtoMinor,
+		Header:     resp.Header,
+		Body:       body,
+		variant:    variant(resp.Header.Values("Vary"), req.Header),
+	}, nil
+}
+
+// toHTTP builds a new http.Response for req from the cached response.
+func (r *Response) toHTTP(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        r.Status,
+		StatusCode:    r.StatusCode,
+		Proto:         r.Proto,
+		ProtoMajor:    r.ProtoMajor,
+		ProtoMinor:    r.ProtoMinor,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,256 @@
+package lazyhttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+	"github.com/arran4/go-be-lazy/lazyhttp"
+)
+
+func newServer(t *testing.T, cacheControl string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		w.Header().Set("Cache-Control", cacheControl)
+		_, _ = io.WriteString(w, "hit "+string(rune('0'+n)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func get(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestCachingTransportServesFromCache(t *testing.T) {
+	srv, hits := newServer(t, "max-age=60")
+	client := &http.Client{Transport: lazyhttp.NewCachingTransport(srv.Client().Transport)}
+
+	if body := get(t, client, srv.URL+"/a"); body != "hit 1" {
+		t.Fatalf("first body %q", body)
+	}
+	if body := get(t, client, srv.URL+"/a"); body != "hit 1" {
+		t.Fatalf("second body %q", body)
+	}
+	if hits.Load() != 1 {
+		t.Fatalf("server hits=%d", hits.Load())
+	}
+
+	// A different URL is a different cache entry.
+	if body := get(t, client, srv.URL+"/b"); body != "hit 2" {
+		t.Fatalf("other body %q", body)
+	}
+}
+
+func TestCachingTransportNoStore(t *testing.T) {
+	srv, hits := newServer(t, "no-store")
+	client := &http.Client{Transport: lazyhttp.NewCachingTransport(srv.Client().Transport)}
+
+	get(t, client, srv.URL)
+	get(t, client, srv.URL)
+	if hits.Load() != 2 {
+		t.Fatalf("server hits=%d", hits.Load())
+	}
+}
+
+func TestCachingTransportCredentialsBypassCache(t *testing.T) {
+	srv, hits := newServer(t, "max-age=60")
+	client := &http.Client{Transport: lazyhttp.NewCachingTransport(srv.Client().Transport)}
+
+	for _, user := range []string{"alice", "bob"} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header.Set("Authorization", "Bearer "+user)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if hits.Load() != 2 {
+		t.Fatalf("server hits=%d, want every authorized request to reach it", hits.Load())
+	}
+}
+
+func TestCachingTransportVary(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		_, _ = io.WriteString(w, r.Header.Get("Accept-Language"))
+	}))
+	t.Cleanup(srv.Close)
+	client := &http.Client{Transport: lazyhttp.NewCachingTransport(srv.Client().Transport)}
+
+	getLang := func(lang string) string {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header.Set("Accept-Language", lang)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	for _, lang := range []string{"en", "fr", "en"} {
+		if body := getLang(lang); body != lang {
+			t.Fatalf("Accept-Language %s got %q", lang, body)
+		}
+	}
+	if hits.Load() != 2 {
+		t.Fatalf("server hits=%d, want the en response reused", hits.Load())
+	}
+}
+
+func TestCachingTransportRequestCacheControl(t *testing.T) {
+	srv, hits := newServer(t, "max-age=60")
+	client := &http.Client{Transport: lazyhttp.NewCachingTransport(srv.Client().Transport)}
+
+	getWith := func(cacheControl string) string {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header.Set("Cache-Control", cacheControl)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	get(t, client, srv.URL)
+	if body := getWith("no-cache"); body != "hit 2" {
+		t.Fatalf("no-cache body %q, want a refetch", body)
+	}
+	if body := get(t, client, srv.URL); body != "hit 2" {
+		t.Fatalf("body %q, want the refetched response cached", body)
+	}
+	if body := getWith("no-store"); body != "hit 3" {
+		t.Fatalf("no-store body %q, want a refetch", body)
+	}
+	if body := get(t, client, srv.URL); body != "hit 2" {
+		t.Fatalf("body %q, want the no-store response left out of the cache", body)
+	}
+	if hits.Load() != 3 {
+		t.Fatalf("server hits=%d", hits.Load())
+	}
+}
+
+func TestCachingTransportCancelDoesNotFailWaiters(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(srv.Close)
+	client := &http.Client{Transport: lazyhttp.NewCachingTransport(srv.Client().Transport)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		_, err := client.Do(req)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan string, 1)
+	go func() {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			second <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		second <- string(body)
+	}()
+	// Give the second request time to join the fetch before the first gives up.
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("first request err=%v, want context.Canceled", err)
+	}
+	close(release)
+	if body := <-second; body != "ok" {
+		t.Fatalf("second body %q", body)
+	}
+}
+
+func TestCachingTransportDropsUnfreshResponses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/missing":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.WriteHeader(http.StatusNotFound)
+		case "/no-cache":
+			w.Header().Set("Cache-Control", "no-cache")
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "*")
+		}
+		_, _ = io.WriteString(w, r.URL.Path)
+	}))
+	t.Cleanup(srv.Close)
+	policy := lazy.NewLRUEvictionPolicy[string, *lazyhttp.Response]()
+	client := &http.Client{Transport: lazyhttp.NewCachingTransport(srv.Client().Transport,
+		lazy.WithEvictionPolicy[string, *lazyhttp.Response](policy))}
+
+	for _, path := range []string{"/fresh", "/missing", "/no-cache", "/vary", "/no-headers"} {
+		if body := get(t, client, srv.URL+path); body != path {
+			t.Fatalf("%s body %q", path, body)
+		}
+	}
+	if got, want := policy.Order(), []string{srv.URL + "/fresh"}; !slices.Equal(got, want) {
+		t.Fatalf("cached %v, want only %v", got, want)
+	}
+}
+
+func TestExpireFromHTTPHeadersUsesTimeSource(t *testing.T) {
+	clock := lazy.NewFakeClock(time.Now())
+	lm := lazy.NewLazyMap[string, *lazyhttp.Response](
+		lazy.WithTimeSource[string, *lazyhttp.Response](clock),
+		lazy.WithExpiry[string, *lazyhttp.Response](lazyhttp.ExpireFromHTTPHeaders()),
+	)
+	fetches := 0
+	fetch := func(string) (*lazyhttp.Response, error) {
+		fetches++
+		return &lazyhttp.Response{StatusCode: http.StatusOK, Header: http.Header{"Cache-Control": {"max-age=60"}}}, nil
+	}
+
+	if _, err := lm.Get("a", fetch); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(59 * time.Second)
+	if _, err := lm.Get("a", fetch); err != nil || fetches != 1 {
+		t.Fatalf("fetches=%d err=%v, want the response still fresh", fetches, err)
+	}
+	clock.Advance(time.Second)
+	if _, err := lm.Get("a", fetch); err != nil || fetches != 2 {
+		t.Fatalf("fetches=%d err=%v, want the response expired by the fake clock", fetches, err)
+	}
+}
@@ -0,0 +1,37 @@
+package lazy
+
+import "errors"
+
+// Log levels passed to Logger.Log.
+const (
+	LevelDebug = "debug"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Logger receives diagnostic events from a map configured with WithLogger. kv holds alternating
+// keys and values, in the style of log/slog, so an implementation can bridge to slog, zap and
+// the like without this package depending on them. Log may be called concurrently and, for
+// removals, after the map lock has been released.
+type Logger interface {
+	Log(level, msg string, kv ...any)
+}
+
+// WithLogger returns an Option that reports evictions and expiries (at LevelDebug), failed
+// fetches (LevelWarn), and recovered panics and failures to close values (LevelError) to
+// logger. Without it nothing is logged.
+func WithLogger[K comparable, V any](logger Logger) Option[K, V] {
+	return func(a *args[K, V]) { a.logger = logger }
+}
+
+// logLoad logs the outcome of a fetch for key, if it failed.
+func (a *args[K, V]) logLoad(key K, err error) {
+	if err == nil {
+		return
+	}
+	if errors.Is(err, ErrFetchPanic) {
+		a.logger.Log(LevelError, "lazy: recovered panic in fetch", "key", key, "error", err)
+		return
+	}
+	a.logger.Log(LevelWarn, "lazy: fetch failed", "key", key, "error", err)
+}
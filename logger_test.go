@@ -0,0 +1,54 @@
+package lazy_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+type capturingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *capturingLogger) Log(level, msg string, kv ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprint(level, " ", msg, " ", kv))
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := &capturingLogger{}
+	lm := lazy.NewLazyMap[string, int](
+		lazy.MaxSize[string, int](1),
+		lazy.WithLogger[string, int](logger),
+		lazy.WithRecover[string, int](),
+	)
+	fetch := func(k string) (int, error) { return len(k), nil }
+
+	Must(lm.Get("a", fetch))
+	// Evicts "a".
+	Must(lm.Get("bb", fetch))
+	// Evicts "bb" and fails.
+	_, _ = lm.Get("c", func(string) (int, error) { return 0, errors.New("down") })
+
+	want := []string{
+		"debug lazy: entry evicted [key a]",
+		"debug lazy: entry evicted [key bb]",
+		"warn lazy: fetch failed [key c error down]",
+	}
+	if !reflect.DeepEqual(logger.lines, want) {
+		t.Fatalf("logged %q, want %q", logger.lines, want)
+	}
+
+	logger.lines = nil
+	_, _ = lm.Get("d", func(string) (int, error) { panic("boom") })
+	if len(logger.lines) != 2 || !strings.HasPrefix(logger.lines[1], "error lazy: recovered panic in fetch [key d") {
+		t.Fatalf("logged %q", logger.lines)
+	}
+}
@@ -0,0 +1,34 @@
+package lazy
+
+import "sync"
+
+// Memoize returns a function that calls fn at most once per distinct argument, caching each
+// result (including errors) in an internal LazyMap configured with opts, so options such as
+// WithExpiry and MaxSize apply. The returned function is safe for concurrent use.
+func Memoize[K comparable, V any](fn func(K) (V, error), opts ...Option[K, V]) func(K) (V, error) {
+	lm := NewLazyMap(opts...)
+	return func(k K) (V, error) { return lm.Get(k, fn) }
+}
+
+// Memoize0 returns a function that calls fn once and then returns its cached result, including
+// an error, on every later call: a lazy singleton backed by a Value. If expiry policies are
+// given, fn is called again once any of them reports the cached result as expired.
+// The returned function is safe for concurrent use.
+func Memoize0[V any](fn func() (V, error), expiry ...Expiry[V]) func() (V, error) {
+	lv := &Value[V]{}
+	if len(expiry) == 0 {
+		return func() (V, error) { return lv.Load(fn) }
+	}
+	e := ExpireWhenAny(expiry...)
+	// mu makes checking and invalidating one step, so concurrent callers don't both
+	// discard the same result, the second throwing away the reload started by the first.
+	var mu sync.Mutex
+	return func() (V, error) {
+		mu.Lock()
+		if lv.IsLoaded() && e.IsExpired(lv) {
+			lv.Invalidate()
+		}
+		mu.Unlock()
+		return lv.Load(fn)
+	}
+}
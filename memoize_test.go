@@ -0,0 +1,95 @@
+package lazy_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestMemoize(t *testing.T) {
+	calls := map[int]int{}
+	square := lazy.Memoize(func(n int) (int, error) {
+		calls[n]++
+		return n * n, nil
+	})
+	for i := 0; i < 3; i++ {
+		for _, n := range []int{2, 3} {
+			if v := Must(square(n)); v != n*n {
+				t.Fatalf("square(%d) = %d", n, v)
+			}
+		}
+	}
+	if calls[2] != 1 || calls[3] != 1 {
+		t.Fatalf("calls = %v", calls)
+	}
+
+	t.Run("expiry", func(t *testing.T) {
+		calls := 0
+		f := lazy.Memoize(func(n int) (int, error) {
+			calls++
+			return n, nil
+		}, lazy.WithExpiry[int, int](lazy.ExpireAfterUses[int](2)))
+		for i := 0; i < 3; i++ {
+			Must(f(1))
+		}
+		if calls != 2 {
+			t.Fatalf("calls = %d, want 2", calls)
+		}
+	})
+}
+
+func TestMemoize0(t *testing.T) {
+	var calls atomic.Int32
+	get := lazy.Memoize0(func() (int, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return 42, nil
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v := Must(get()); v != 42 {
+				t.Errorf("got %d", v)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+
+	t.Run("error is cached", func(t *testing.T) {
+		calls := 0
+		boom := errors.New("boom")
+		get := lazy.Memoize0(func() (int, error) {
+			calls++
+			return 0, boom
+		})
+		for i := 0; i < 2; i++ {
+			if _, err := get(); !errors.Is(err, boom) {
+				t.Fatalf("err = %v", err)
+			}
+		}
+		if calls != 1 {
+			t.Fatalf("calls = %d, want 1", calls)
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		calls := 0
+		get := lazy.Memoize0(func() (int, error) {
+			calls++
+			return calls, nil
+		}, lazy.ExpireAfterUses[int](2))
+		got := []int{Must(get()), Must(get()), Must(get())}
+		if got[0] != 1 || got[1] != 1 || got[2] != 2 {
+			t.Fatalf("got %v", got)
+		}
+	})
+}
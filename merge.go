@@ -0,0 +1,26 @@
+package lazy
+
+// WithMerge returns an Option that combines each newly fetched value with the value it
+// replaces, caching merge(old, new) instead of new, e.g. to accumulate pages into a growing
+// slice. It applies when a loaded value is fetched again, by Refresh or after it expires; the
+// first load of a key, and reloads of a value cached with an error, store the fetched value
+// as is. merge runs as part of the fetch, so writes back and events see the merged value. old
+// may still be in use by other callers, so merge shouldn't modify it in place: for slices,
+// use slices.Concat rather than append.
+func WithMerge[K comparable, V any](merge func(old, new V) V) Option[K, V] {
+	return func(a *args[K, V]) { a.merge = merge }
+}
+
+// merging wraps fetch so that a successful result is merged with prior's value, if it has one.
+func (a *args[K, V]) merging(fetch func(K) (V, error), prior *Value[V]) func(K) (V, error) {
+	return func(k K) (V, error) {
+		v, err := fetch(k)
+		if err != nil {
+			return v, err
+		}
+		if old, ok, perr := prior.Value(); ok && perr == nil {
+			return a.merge(old, v), nil
+		}
+		return v, nil
+	}
+}
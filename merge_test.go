@@ -0,0 +1,32 @@
+package lazy_test
+
+import (
+	"slices"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestWithMerge(t *testing.T) {
+	lm := lazy.NewLazyMap[string, []int](lazy.WithMerge[string, []int](func(old, new []int) []int {
+		return slices.Concat(old, new)
+	}))
+	page := 0
+	fetch := func(string) ([]int, error) {
+		page++
+		return []int{page * 10, page*10 + 1}, nil
+	}
+
+	if v := Must(lm.Get("feed", fetch)); !slices.Equal(v, []int{10, 11}) {
+		t.Fatalf("Expected the first page as is, got %v", v)
+	}
+	if v := Must(lm.Get("feed", fetch)); !slices.Equal(v, []int{10, 11}) {
+		t.Fatalf("Expected the cached value, got %v", v)
+	}
+	if v := Must(lm.Get("feed", fetch, lazy.Refresh[string, []int]())); !slices.Equal(v, []int{10, 11, 20, 21}) {
+		t.Fatalf("Expected the refresh to append the second page, got %v", v)
+	}
+	if v := Must(lm.Get("feed", fetch, lazy.Refresh[string, []int]())); !slices.Equal(v, []int{10, 11, 20, 21, 30, 31}) {
+		t.Fatalf("Expected the refresh to append the third page, got %v", v)
+	}
+}
@@ -0,0 +1,93 @@
+package lazy
+
+import (
+	"time"
+)
+
+// Metrics receives instrumentation events from Map and LazyMap.
+// Implementations can forward them to a metrics system such as Prometheus or OpenTelemetry
+// without this package depending on it. Hooks are called without the map lock held, but may
+// be called concurrently, so implementations must be safe for concurrent use.
+type Metrics[K comparable] interface {
+	// OnHit is called when a lookup is served from the cache.
+	OnHit(key K)
+	// OnMiss is called when a lookup can't be served from the cache, before any fetch.
+	OnMiss(key K)
+	// OnLoad is called after a fetch for key completes, with how long it took and its error.
+	OnLoad(key K, d time.Duration, err error)
+	// OnEvict is called when key is evicted to keep the map within MaxSize.
+	OnEvict(key K)
+	// OnExpire is called when key is removed because its Expiry policy reported it expired.
+	OnExpire(key K)
+}
+
+// NoopMetrics is a Metrics implementation that ignores every event.
+// It can be embedded to implement only some of the hooks.
+type NoopMetrics[K comparable] struct{}
+
+func (NoopMetrics[K]) OnHit(K)                        {}
+func (NoopMetrics[K]) OnMiss(K)                       {}
+func (NoopMetrics[K]) OnLoad(K, time.Duration, error) {}
+func (NoopMetrics[K]) OnEvict(K)                      {}
+func (NoopMetrics[K]) OnExpire(K)                     {}
+
+// WithMetrics returns an Option that reports cache events to m. A nil m disables reporting.
+func WithMetrics[K comparable, V any](m Metrics[K]) Option[K, V] {
+	return func(a *args[K, V]) { a.metrics = m }
+}
+
+func (a *args[K, V]) hit(key K) {
+	if a.stats != nil {
+		a.stats.hits.Add(1)
+	}
+	if a.metrics != nil {
+		a.metrics.OnHit(key)
+	}
+}
+
+func (a *args[K, V]) miss(key K) {
+	if a.stats != nil {
+		a.stats.misses.Add(1)
+	}
+	if a.metrics != nil {
+		a.metrics.OnMiss(key)
+	}
+}
+
+// WithOnLoadStart returns an Option that calls fn with the key right before each fetch runs.
+// Cache hits don't trigger it. It's intended for ad-hoc tracing and logging; see WithMetrics
+// for instrumentation.
+func WithOnLoadStart[K comparable, V any](fn func(K)) Option[K, V] {
+	return func(a *args[K, V]) { a.onLoadStart = fn }
+}
+
+// WithOnLoadEnd returns an Option that calls fn after each fetch completes, with the key,
+// the fetched value and error, and how long the fetch took. Cache hits don't trigger it.
+func WithOnLoadEnd[K comparable, V any](fn func(K, V, error, time.Duration)) Option[K, V] {
+	return func(a *args[K, V]) { a.onLoadEnd = fn }
+}
+
+// timed wraps fn so that its duration and error are reported to OnLoad and the load hooks.
+func (a *args[K, V]) timed(key K, fn func() (V, error)) func() (V, error) {
+	if a.metrics == nil && a.logger == nil && a.onLoadStart == nil && a.onLoadEnd == nil {
+		return fn
+	}
+	return func() (V, error) {
+		if a.onLoadStart != nil {
+			a.onLoadStart(key)
+		}
+		start := time.Now()
+		v, err := fn()
+		d := time.Since(start)
+		if a.metrics != nil {
+			a.metrics.OnLoad(key, d, err)
+		}
+		if a.logger != nil {
+			a.logLoad(key, err)
+		}
+		if a.onLoadEnd != nil {
+			a.onLoadEnd(key, v, err, d)
+		}
+		return v, err
+	}
+}
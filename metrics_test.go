@@ -0,0 +1,108 @@
+package lazy_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+type recordingMetrics struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recordingMetrics) record(format string, a ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, fmt.Sprintf(format, a...))
+}
+
+func (r *recordingMetrics) OnHit(key string)  { r.record("hit %s", key) }
+func (r *recordingMetrics) OnMiss(key string) { r.record("miss %s", key) }
+func (r *recordingMetrics) OnLoad(key string, d time.Duration, err error) {
+	r.record("load %s %v", key, err)
+}
+func (r *recordingMetrics) OnEvict(key string)  { r.record("evict %s", key) }
+func (r *recordingMetrics) OnExpire(key string) { r.record("expire %s", key) }
+
+func TestWithMetricsMissThenHit(t *testing.T) {
+	rec := &recordingMetrics{}
+	lm := lazy.NewLazyMap[string, int](lazy.WithMetrics[string, int](rec))
+	fetch := func(string) (int, error) { return 1, nil }
+
+	_, _ = lm.Get("a", fetch)
+	_, _ = lm.Get("a", fetch)
+
+	want := []string{"miss a", "load a <nil>", "hit a"}
+	if !reflect.DeepEqual(rec.events, want) {
+		t.Fatalf("events=%q want %q", rec.events, want)
+	}
+}
+
+func TestWithMetricsEvictExpireAndError(t *testing.T) {
+	rec := &recordingMetrics{}
+	lm := lazy.NewLazyMap[string, int](
+		lazy.WithMetrics[string, int](rec),
+		lazy.MaxSize[string, int](1),
+		lazy.WithEvictionPolicy[string, int](lazy.NewFIFOEvictionPolicy[string, int]()),
+	)
+	bad := errors.New("bad")
+	_, _ = lm.Get("a", func(string) (int, error) { return 0, bad })
+	_, _ = lm.Get("b", func(string) (int, error) { return 2, nil })
+	_, _ = lm.Get("b", func(string) (int, error) { return 3, nil },
+		lazy.WithExpiry[string, int](lazy.ExpireAfter[int](-time.Second)))
+
+	want := []string{
+		"miss a", "load a bad",
+		"evict a", "miss b", "load b <nil>",
+		"expire b", "miss b", "load b <nil>",
+	}
+	if !reflect.DeepEqual(rec.events, want) {
+		t.Fatalf("events=%q want %q", rec.events, want)
+	}
+}
+
+func TestWithMetricsNil(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](lazy.WithMetrics[string, int](nil))
+	if v, err := lm.Get("a", func(string) (int, error) { return 1, nil }); err != nil || v != 1 {
+		t.Fatalf("got %v %v", v, err)
+	}
+	var _ lazy.Metrics[string] = lazy.NoopMetrics[string]{}
+}
+
+func TestLoadHooks(t *testing.T) {
+	var events []string
+	var gotDur time.Duration
+	var gotErr error
+	bad := errors.New("bad")
+	lm := lazy.NewLazyMap[string, int](
+		lazy.WithOnLoadStart[string, int](func(k string) { events = append(events, "start "+k) }),
+		lazy.WithOnLoadEnd[string, int](func(k string, v int, err error, d time.Duration) {
+			events = append(events, fmt.Sprintf("end %s %d", k, v))
+			gotErr, gotDur = err, d
+		}),
+	)
+
+	_, _ = lm.Get("a", func(string) (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 1, nil
+	})
+	if gotDur < 10*time.Millisecond || gotErr != nil {
+		t.Fatalf("duration=%v err=%v", gotDur, gotErr)
+	}
+	_, _ = lm.Get("a", func(string) (int, error) { return 2, nil })
+	_, _ = lm.Get("b", func(string) (int, error) { return 0, bad })
+	if !errors.Is(gotErr, bad) {
+		t.Fatalf("err=%v", gotErr)
+	}
+
+	want := []string{"start a", "end a 1", "start b", "end b 0"}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events=%q want %q", events, want)
+	}
+}
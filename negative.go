@@ -0,0 +1,36 @@
+package lazy
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned, possibly wrapped, by fetch functions to report that a key doesn't
+// exist, as opposed to the backend failing. Map caches it as a definitive negative result: a
+// cache hit returns the error again without fetching, for as long as WithNegativeCache allows.
+var ErrNotFound = errors.New("not found")
+
+// IsNotFound reports whether err is or wraps ErrNotFound.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// WithNegativeCache returns an Option that keeps a not-found result (see ErrNotFound) for ttl,
+// after which the key is fetched again. Other fetch errors are treated as transient and aren't
+// served from the cache: the next access fetches again.
+func WithNegativeCache[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(a *args[K, V]) { a.negativeTTL = ttl }
+}
+
+// negativeExpired reports whether lv holds an error that WithNegativeCache no longer allows
+// to be served: a transient error, or a not-found result older than the TTL.
+func (a *args[K, V]) negativeExpired(lv *Value[V]) bool {
+	if a.negativeTTL <= 0 {
+		return false
+	}
+	r := lv.val.Load()
+	if r == nil || r.err == nil {
+		return false
+	}
+	return !IsNotFound(r.err) || lv.now().Sub(r.created()) > a.negativeTTL
+}
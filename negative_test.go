@@ -0,0 +1,71 @@
+package lazy_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestWithNegativeCache(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](lazy.WithNegativeCache[string, int](50 * time.Millisecond))
+	fetches := 0
+	fetch := func(k string) (int, error) {
+		fetches++
+		return 0, fmt.Errorf("user %q: %w", k, lazy.ErrNotFound)
+	}
+
+	if _, err := lm.Get("alice", fetch); !lazy.IsNotFound(err) {
+		t.Fatalf("Expected a not-found error, got %v", err)
+	}
+	if _, err := lm.Get("alice", fetch); !lazy.IsNotFound(err) {
+		t.Fatalf("Expected the cached not-found error, got %v", err)
+	}
+	if fetches != 1 {
+		t.Fatalf("Expected 1 fetch within the TTL, got %d", fetches)
+	}
+
+	// A cached negative is distinguishable from a key that was never fetched.
+	_, err := lm.Get("alice", nil, lazy.DontFetch[string, int](), lazy.MustBeCached[string, int]())
+	if !lazy.IsNotFound(err) {
+		t.Fatalf("Expected a not-found error for the cached negative, got %v", err)
+	}
+	_, err = lm.Get("bob", nil, lazy.DontFetch[string, int](), lazy.MustBeCached[string, int]())
+	if !errors.Is(err, lazy.ErrValueNotCached) {
+		t.Fatalf("Expected ErrValueNotCached for an unfetched key, got %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, err := lm.Get("alice", fetch); !lazy.IsNotFound(err) {
+		t.Fatalf("Expected a not-found error, got %v", err)
+	}
+	if fetches != 2 {
+		t.Fatalf("Expected a refetch once the TTL passed, got %d fetches", fetches)
+	}
+}
+
+func TestWithNegativeCacheTransientError(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](lazy.WithNegativeCache[string, int](time.Minute))
+	fail := true
+	fetches := 0
+	fetch := func(k string) (int, error) {
+		fetches++
+		if fail {
+			return 0, errors.New("backend unavailable")
+		}
+		return 1, nil
+	}
+
+	if _, err := lm.Get("a", fetch); err == nil || lazy.IsNotFound(err) {
+		t.Fatalf("Expected the backend error, got %v", err)
+	}
+	fail = false
+	if v, err := lm.Get("a", fetch); err != nil || v != 1 {
+		t.Fatalf("Expected the transient error to be retried, got %v, %v", v, err)
+	}
+	if fetches != 2 {
+		t.Fatalf("Expected 2 fetches, got %d", fetches)
+	}
+}
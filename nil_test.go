@@ -0,0 +1,62 @@
+package lazy_test
+
+import (
+	"fmt"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+type foo struct{ n int }
+
+func TestLazyMapCachesNilPointer(t *testing.T) {
+	lm := lazy.NewLazyMap[string, *foo]()
+	calls := 0
+	fetch := func(string) (*foo, error) { calls++; return nil, nil }
+
+	for i := 0; i < 3; i++ {
+		if v, err := lm.Get("a", fetch); err != nil || v != nil {
+			t.Fatalf("got %v %v", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("cached nil was refetched, calls=%d", calls)
+	}
+	if v, ok := lm.GetIfPresent("a"); !ok || v != nil {
+		t.Fatalf("GetIfPresent got %v %v", v, ok)
+	}
+
+	// A cached nil is a hit, so DefaultValue doesn't replace it.
+	def := &foo{n: 1}
+	if v, err := lm.Get("a", nil, lazy.DontFetch[string, *foo](), lazy.DefaultValue[string, *foo](def)); err != nil || v != nil {
+		t.Fatalf("DontFetch on cached nil got %v %v", v, err)
+	}
+}
+
+func TestDefaultValueNilPointer(t *testing.T) {
+	lm := lazy.NewLazyMap[string, *foo](lazy.DefaultValue[string, *foo](nil))
+	if v, err := lm.Get("missing", nil, lazy.DontFetch[string, *foo]()); err != nil || v != nil {
+		t.Fatalf("DontFetch got %v %v", v, err)
+	}
+	v, err := lm.Get("failed", func(string) (*foo, error) { return &foo{}, fmt.Errorf("bad") })
+	if err != nil || v != nil {
+		t.Fatalf("fetch error got %v %v", v, err)
+	}
+	if v, ok := lm.GetIfPresent("failed"); !ok || v != nil {
+		t.Fatalf("nil default not cached: %v %v", v, ok)
+	}
+}
+
+func TestLazyMapCachesNilInterface(t *testing.T) {
+	lm := lazy.NewLazyMap[string, fmt.Stringer]()
+	calls := 0
+	fetch := func(string) (fmt.Stringer, error) { calls++; return nil, nil }
+	for i := 0; i < 2; i++ {
+		if v, err := lm.Get("a", fetch); err != nil || v != nil {
+			t.Fatalf("got %v %v", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("calls=%d", calls)
+	}
+}
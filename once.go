@@ -0,0 +1,32 @@
+package lazy
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Once is like sync.Once, but Do returns the value and error produced by its function.
+// It is a lighter alternative to Value for when no usage tracking or expiry is needed.
+// The zero Once is ready to use. A Once must not be copied after first use.
+type Once[T any] struct {
+	val atomic.Pointer[result[T]]
+	mu  sync.Mutex
+}
+
+// Do calls fn if and only if Do is being called for the first time for this Once, and returns
+// its result. Every later call returns the same value and error, without calling its fn;
+// calls made while fn is running wait for it to finish. As with sync.Once, if fn calls Do on
+// the same Once it deadlocks. Safe for concurrent use.
+func (o *Once[T]) Do(fn func() (T, error)) (T, error) {
+	if r := o.val.Load(); r != nil {
+		return r.value, r.err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if r := o.val.Load(); r != nil {
+		return r.value, r.err
+	}
+	v, err := fn()
+	o.val.Store(&result[T]{value: v, err: err})
+	return v, err
+}
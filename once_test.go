@@ -0,0 +1,49 @@
+package lazy_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestOnceDo(t *testing.T) {
+	var o lazy.Once[int]
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := o.Do(func() (int, error) {
+				calls.Add(1)
+				time.Sleep(10 * time.Millisecond)
+				return 42, nil
+			})
+			if err != nil || got != 42 {
+				t.Errorf("got %v %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("calls=%d", n)
+	}
+	if got, _ := o.Do(func() (int, error) { return 99, nil }); got != 42 {
+		t.Fatalf("later Do got %d", got)
+	}
+}
+
+func TestOnceDoError(t *testing.T) {
+	var o lazy.Once[int]
+	firstErr := errors.New("bad")
+	if _, err := o.Do(func() (int, error) { return 0, firstErr }); err != firstErr {
+		t.Fatalf("err=%v", err)
+	}
+	if v, err := o.Do(func() (int, error) { return 1, nil }); err != firstErr || v != 0 {
+		t.Fatalf("second Do v=%d err=%v", v, err)
+	}
+}
@@ -0,0 +1,44 @@
+package lazy
+
+import (
+	"cmp"
+	"slices"
+)
+
+// OrderedLazyMap is a LazyMap with ordered keys, which adds range scans over the keys.
+type OrderedLazyMap[K cmp.Ordered, V any] struct {
+	*LazyMap[K, V]
+}
+
+// NewOrderedLazyMap creates a new OrderedLazyMap with optional default settings.
+func NewOrderedLazyMap[K cmp.Ordered, V any](opts ...Option[K, V]) *OrderedLazyMap[K, V] {
+	return &OrderedLazyMap[K, V]{LazyMap: NewLazyMap(opts...)}
+}
+
+// RangeKeys calls fn, in ascending key order, for each loaded, non-errored entry whose key is
+// in [lo, hi], stopping if fn returns false. The map is unordered, so the matching entries are
+// collected and sorted first; the read lock is only held while collecting, and fn sees the
+// entries as they were at that point. No usage is recorded.
+func (om *OrderedLazyMap[K, V]) RangeKeys(lo, hi K, fn func(K, V) bool) {
+	type entry struct {
+		key   K
+		value V
+	}
+	var entries []entry
+	om.mu.RLock()
+	for k, lv := range om.m {
+		if k < lo || k > hi {
+			continue
+		}
+		if v, ok, err := lv.Value(); ok && err == nil {
+			entries = append(entries, entry{key: k, value: v})
+		}
+	}
+	om.mu.RUnlock()
+	slices.SortFunc(entries, func(a, b entry) int { return cmp.Compare(a.key, b.key) })
+	for _, e := range entries {
+		if !fn(e.key, e.value) {
+			return
+		}
+	}
+}
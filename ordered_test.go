@@ -0,0 +1,36 @@
+package lazy_test
+
+import (
+	"reflect"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestOrderedLazyMapRangeKeys(t *testing.T) {
+	om := lazy.NewOrderedLazyMap[int, string]()
+	fetch := func(k int) (string, error) { return string(rune('a' + k)), nil }
+	for _, k := range []int{9, 3, 7, 1, 5, 0, 8, 2, 6, 4} {
+		Must(om.Get(k, fetch))
+	}
+
+	var keys []int
+	var values []string
+	om.RangeKeys(3, 6, func(k int, v string) bool {
+		keys = append(keys, k)
+		values = append(values, v)
+		return true
+	})
+	if !reflect.DeepEqual(keys, []int{3, 4, 5, 6}) || !reflect.DeepEqual(values, []string{"d", "e", "f", "g"}) {
+		t.Fatalf("keys=%v values=%v", keys, values)
+	}
+
+	keys = nil
+	om.RangeKeys(0, 100, func(k int, _ string) bool {
+		keys = append(keys, k)
+		return len(keys) < 2
+	})
+	if !reflect.DeepEqual(keys, []int{0, 1}) {
+		t.Fatalf("early stop keys=%v", keys)
+	}
+}
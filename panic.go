@@ -0,0 +1,50 @@
+package lazy
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrFetchPanic is matched, via errors.Is, by the error returned for a fetch that panicked
+// while WithRecover was in effect.
+var ErrFetchPanic = errors.New("fetch panicked")
+
+// PanicError is the error returned for a fetch that panicked while WithRecover was in effect.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v: %v\n%s", ErrFetchPanic, e.Value, e.Stack)
+}
+
+func (e *PanicError) Unwrap() error {
+	return ErrFetchPanic
+}
+
+// WithRecover returns an Option that recovers a panic in the fetch function and turns it into
+// a *PanicError, which is then handled like any other fetch error (cached, retried, replaced by
+// a DefaultValue, ...). Without it a panicking fetch crashes the program.
+func WithRecover[K comparable, V any]() Option[K, V] {
+	return func(a *args[K, V]) { a.recover = true }
+}
+
+// recovering wraps fn so that a panic is returned as a *PanicError if WithRecover is set.
+func (a *args[K, V]) recovering(fn func() (V, error)) func() (V, error) {
+	if !a.recover {
+		return fn
+	}
+	return func() (v V, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				var zero V
+				v, err = zero, &PanicError{Value: p, Stack: debug.Stack()}
+			}
+		}()
+		return fn()
+	}
+}
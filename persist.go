@@ -0,0 +1,201 @@
+package lazy
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+// jsonEntry is the encoding of a single entry when keys can't be used as JSON object keys.
+type jsonEntry[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// gobEntry is the encoding of a single entry in a gob snapshot.
+type gobEntry[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// stringKeyed reports whether K is encoded as a JSON object rather than an array of entries.
+func stringKeyed[K comparable]() bool {
+	return reflect.TypeFor[K]().Kind() == reflect.String
+}
+
+// loaded returns the loaded, non-errored entries of the map.
+// Unloaded and errored entries are skipped, and no usage is recorded.
+func (lm *LazyMap[K, V]) loaded() map[K]V {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+	out := make(map[K]V, len(lm.m))
+	for k, lv := range lm.m {
+		if v, ok, err := lv.Value(); ok && err == nil {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// storeAll stores entries as loaded values. Like Put, it overwrites existing entries in place,
+// keeping their identity, and only replaces those that have expired. New keys respect MaxSize,
+// evicting as needed.
+func (lm *LazyMap[K, V]) storeAll(entries map[K]V) {
+	a := lm.config()
+	var removals []removal[K, V]
+	type overwrite struct {
+		k  K
+		lv *Value[V]
+		v  V
+	}
+	// overwrites are existing entries to update once the lock is released, as Overwrite waits
+	// for any load in progress, whose fetch may need the map lock.
+	var overwrites []overwrite
+	var crossedSize int
+	lm.mu.Lock()
+	if lm.m == nil {
+		lm.m = make(map[K]*Value[V], len(entries))
+	}
+	for k, v := range entries {
+		old, ok := lm.m[k]
+		if ok && !a.isExpired(old) {
+			overwrites = append(overwrites, overwrite{k, old, v})
+			if a.evictionPolicy != nil {
+				a.evictionPolicy.Access(k)
+			}
+			continue
+		}
+		if ok {
+			why := a.expiryReason(old)
+			removals = append(removals, removal[K, V]{key: k, value: old, reason: expiredBy(why), why: why})
+		} else if a.maxSize > 0 && len(lm.m) >= a.maxSize {
+			if victim, lv, found := evict(lm.m, a.evictionPolicy, k); found {
+				removals = append(removals, removal[K, V]{key: victim, value: lv, reason: RemovalEvicted})
+			}
+		}
+		lv := a.newValue(k)
+		lv.Store(v)
+		if ok {
+			lv.markIf(flagPinned, old.has(flagPinned))
+		}
+		lm.m[k] = lv
+		if !ok && a.highWater.inserted(len(lm.m), a.maxSize) {
+			crossedSize = len(lm.m)
+		}
+		// The policy has to see each key as it goes in, so that later evictions in this batch
+		// prefer older entries over the ones just stored.
+		if a.evictionPolicy != nil {
+			if !ok {
+				insert(a.evictionPolicy, k)
+			}
+			a.evictionPolicy.Access(k)
+		}
+	}
+	a.republish(lm.m)
+	lm.mu.Unlock()
+	a.removed(removals)
+	for _, o := range overwrites {
+		a.closeDisplaced(o.k, o.lv.overwrite(o.v), o.v)
+	}
+	if crossedSize > 0 {
+		a.highWater.fn(crossedSize, a.maxSize)
+	}
+	if a.events != nil {
+		for k, v := range entries {
+			a.publish(EventSet, k, v)
+		}
+	}
+}
+
+// MarshalJSON encodes the loaded, non-errored entries of the map.
+// Maps with string keys are encoded as a JSON object; other maps as an array of
+// {"key": ..., "value": ...} objects. Unloaded and errored entries are skipped.
+func (lm *LazyMap[K, V]) MarshalJSON() ([]byte, error) {
+	entries := lm.loaded()
+	if stringKeyed[K]() {
+		return json.Marshal(entries)
+	}
+	list := make([]jsonEntry[K, V], 0, len(entries))
+	for k, v := range entries {
+		list = append(list, jsonEntry[K, V]{Key: k, Value: v})
+	}
+	return json.Marshal(list)
+}
+
+// UnmarshalJSON decodes entries produced by MarshalJSON and stores them as loaded values,
+// so they are served from the cache without calling any fetch function.
+// Existing entries with the same keys are replaced.
+func (lm *LazyMap[K, V]) UnmarshalJSON(data []byte) error {
+	entries := make(map[K]V)
+	if stringKeyed[K]() {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+	} else {
+		var list []jsonEntry[K, V]
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		for _, e := range list {
+			entries[e.Key] = e.Value
+		}
+	}
+	lm.storeAll(entries)
+	return nil
+}
+
+// WriteTo writes a gob-encoded snapshot of the loaded, non-errored entries of the map to w.
+// K and V must be encodable with encoding/gob. It returns the number of bytes written.
+func (lm *LazyMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	entries := lm.loaded()
+	list := make([]gobEntry[K, V], 0, len(entries))
+	for k, v := range entries {
+		list = append(list, gobEntry[K, V]{Key: k, Value: v})
+	}
+	cw := &countingWriter{w: w}
+	err := gob.NewEncoder(cw).Encode(list)
+	return cw.n, err
+}
+
+// ReadFrom reads a snapshot written by WriteTo and stores its entries as loaded values,
+// so they are served from the cache without calling any fetch function.
+// Existing entries with the same keys are replaced. It returns the number of bytes read,
+// which may include bytes past the end of the snapshot consumed by buffering.
+func (lm *LazyMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	var list []gobEntry[K, V]
+	if err := gob.NewDecoder(cr).Decode(&list); err != nil {
+		return cr.n, err
+	}
+	entries := make(map[K]V, len(list))
+	for _, e := range list {
+		entries[e.Key] = e.Value
+	}
+	lm.storeAll(entries)
+	return cr.n, nil
+}
@@ -0,0 +1,122 @@
+package lazy_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func noFetch[K comparable, V any](t *testing.T) func(K) (V, error) {
+	return func(k K) (V, error) {
+		t.Errorf("unexpected fetch for %v", k)
+		var zero V
+		return zero, errors.New("unexpected fetch")
+	}
+}
+
+func TestLazyMapJSONRoundTrip(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	lm.Set("a", 1)
+	lm.Set("b", 2)
+
+	data, err := json.Marshal(lm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"a":1,"b":2}` {
+		t.Fatalf("json=%s", data)
+	}
+
+	restored := lazy.NewLazyMap[string, int]()
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]int{"a": 1, "b": 2} {
+		if v, err := restored.Get(k, noFetch[string, int](t)); err != nil || v != want {
+			t.Fatalf("%s: got %v %v", k, v, err)
+		}
+	}
+}
+
+func TestLazyMapJSONNonStringKeys(t *testing.T) {
+	lm := lazy.NewLazyMap[int, string]()
+	lm.Set(7, "seven")
+
+	data, err := json.Marshal(lm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `[{"key":7,"value":"seven"}]` {
+		t.Fatalf("json=%s", data)
+	}
+
+	restored := lazy.NewLazyMap[int, string]()
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := restored.Get(7, noFetch[int, string](t)); err != nil || v != "seven" {
+		t.Fatalf("got %v %v", v, err)
+	}
+}
+
+func TestLazyMapJSONSkipsErrored(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	lm.Set("good", 1)
+	if _, err := lm.Get("bad", func(string) (int, error) { return 0, errors.New("bad") }); err == nil {
+		t.Fatal("expected error")
+	}
+
+	data, err := json.Marshal(lm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"good":1}` {
+		t.Fatalf("json=%s", data)
+	}
+}
+
+func TestLazyMapGobRoundTrip(t *testing.T) {
+	lm := lazy.NewLazyMap[string, []byte]()
+	lm.Set("a", []byte("alpha"))
+	lm.Set("b", []byte("beta"))
+	// Unloaded and errored entries are skipped.
+	if _, err := lm.Get("bad", func(string) ([]byte, error) { return nil, errors.New("bad") }); err == nil {
+		t.Fatal("expected error")
+	}
+
+	var buf bytes.Buffer
+	n, err := lm.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("WriteTo reported %d bytes, wrote %d", n, buf.Len())
+	}
+
+	restored := lazy.NewLazyMap[string, []byte]()
+	if _, err := restored.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]string{"a": "alpha", "b": "beta"} {
+		if v, err := restored.Get(k, noFetch[string, []byte](t)); err != nil || string(v) != want {
+			t.Fatalf("%s: got %q %v", k, v, err)
+		}
+	}
+	if _, err := restored.Get("bad", nil, lazy.DontFetch[string, []byte](), lazy.MustBeCached[string, []byte]()); !errors.Is(err, lazy.ErrValueNotCached) {
+		t.Fatalf("errored entry restored: %v", err)
+	}
+}
+
+func TestLazyMapGobEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := lazy.NewLazyMap[string, []byte]().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored := lazy.NewLazyMap[string, []byte]()
+	if _, err := restored.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,55 @@
+package lazy
+
+// Pin exempts key from MaxSize eviction until it is unpinned or removed. If the key isn't in
+// the map yet, an empty entry is added for it so the pin applies once it is loaded; like any new
+// entry, it counts towards MaxSize, evicting another entry if needed, and is passed to the
+// eviction policy. When every entry is pinned the map is allowed to grow past MaxSize rather
+// than evict a pinned one. Expiry, Clear and Remove still apply to pinned entries; removing an
+// entry drops its pin.
+func (lm *LazyMap[K, V]) Pin(key K) {
+	a := lm.config()
+	var removals []removal[K, V]
+	var crossedSize int
+	lm.mu.Lock()
+	if lm.m == nil {
+		lm.m = make(map[K]*Value[V])
+	}
+	lv, ok := lm.m[key]
+	if ok {
+		lv.mark(flagPinned)
+		lm.mu.Unlock()
+		return
+	}
+	for a.maxSize > 0 && len(lm.m) >= a.maxSize {
+		k, victim, found := evict(lm.m, a.evictionPolicy, key)
+		if !found {
+			break
+		}
+		removals = append(removals, removal[K, V]{key: k, value: victim, reason: RemovalEvicted})
+	}
+	lv = a.newValue(key)
+	lv.mark(flagPinned)
+	lm.m[key] = lv
+	if a.highWater.inserted(len(lm.m), a.maxSize) {
+		crossedSize = len(lm.m)
+	}
+	a.republish(lm.m)
+	lm.mu.Unlock()
+	a.removed(removals)
+	if a.evictionPolicy != nil {
+		insert(a.evictionPolicy, key)
+		a.evictionPolicy.Access(key)
+	}
+	if crossedSize > 0 {
+		a.highWater.fn(crossedSize, a.maxSize)
+	}
+}
+
+// Unpin makes key evictable again. It does nothing if the key isn't in the map.
+func (lm *LazyMap[K, V]) Unpin(key K) {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+	if lv, ok := lm.m[key]; ok {
+		lv.unmark(flagPinned)
+	}
+}
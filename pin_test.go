@@ -0,0 +1,123 @@
+package lazy_test
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestLazyMapPin(t *testing.T) {
+	lm := lazy.NewLazyMap[int, int](
+		lazy.MaxSize[int, int](3),
+		lazy.WithEvictionPolicy[int, int](lazy.NewLRUEvictionPolicy[int, int]()),
+	)
+	fetch := func(k int) (int, error) { return k, nil }
+	lm.Pin(0)
+	Must(lm.Get(0, fetch))
+
+	for i := 1; i <= 100; i++ {
+		Must(lm.Get(i, fetch))
+	}
+	if v, ok := lm.GetIfPresent(0); !ok || v != 0 {
+		t.Fatal("pinned key was evicted")
+	}
+	if lm.Len() != 3 {
+		t.Fatalf("Len=%d", lm.Len())
+	}
+
+	// Refreshing replaces the entry but keeps the pin.
+	Must(lm.Get(0, fetch, lazy.Refresh[int, int]()))
+	lm.Unpin(0)
+	for i := 101; i <= 103; i++ {
+		Must(lm.Get(i, fetch))
+	}
+	if _, ok := lm.GetIfPresent(0); ok {
+		t.Fatal("unpinned key survived a flood")
+	}
+}
+
+func TestLazyMapAllPinnedExceedsMaxSize(t *testing.T) {
+	lm := lazy.NewLazyMap[int, int](lazy.MaxSize[int, int](2))
+	fetch := func(k int) (int, error) { return k, nil }
+	for i := 0; i < 4; i++ {
+		lm.Pin(i)
+		Must(lm.Get(i, fetch))
+	}
+	if lm.Len() != 4 {
+		t.Fatalf("Len=%d", lm.Len())
+	}
+}
+
+func TestLazyMapPinAbsentKeyRespectsMaxSize(t *testing.T) {
+	lru := lazy.NewLRUEvictionPolicy[int, int]()
+	lm := lazy.NewLazyMap[int, int](lazy.MaxSize[int, int](2), lazy.WithEvictionPolicy[int, int](lru))
+	fetch := func(k int) (int, error) { return k, nil }
+	Must(lm.Get(1, fetch))
+	Must(lm.Get(2, fetch))
+
+	lm.Pin(0)
+	if lm.Len() != 2 {
+		t.Fatalf("Len=%d, want Pin to make room within MaxSize", lm.Len())
+	}
+	if _, ok := lm.GetIfPresent(1); ok {
+		t.Fatal("Expected the least recently used key to make room for the pinned one")
+	}
+	if !slices.Contains(lru.Order(), 0) {
+		t.Fatalf("Order() = %v, policy wasn't told about the pinned key", lru.Order())
+	}
+}
+
+// absentVictimPolicy always selects a key that isn't in the map.
+type absentVictimPolicy struct{}
+
+func (absentVictimPolicy) Access(int) {}
+
+func (absentVictimPolicy) SelectVictim(map[int]*lazy.Value[int]) (int, bool) { return -1, true }
+
+func TestEvictAbsentVictim(t *testing.T) {
+	var m map[int]*lazy.Value[int]
+	var mu sync.RWMutex
+	fetch := func(k int) (int, error) { return k, nil }
+	opts := []lazy.Option[int, int]{
+		lazy.MaxSize[int, int](2),
+		lazy.WithEvictionPolicy[int, int](absentVictimPolicy{}),
+	}
+	for i := 0; i < 5; i++ {
+		Must(lazy.Map(&m, &mu, i, fetch, opts...))
+	}
+	if len(m) != 2 {
+		t.Fatalf("len=%d, want 2", len(m))
+	}
+}
+
+func TestPinnedVictimStaysTracked(t *testing.T) {
+	fetch := func(k int) (int, error) { return k, nil }
+
+	lru := lazy.NewLRUEvictionPolicy[int, int]()
+	lm := lazy.NewLazyMap[int, int](lazy.MaxSize[int, int](2), lazy.WithEvictionPolicy[int, int](lru))
+	lm.Pin(0)
+	Must(lm.Get(0, fetch))
+	Must(lm.Get(1, fetch))
+	Must(lm.Get(2, fetch))
+	if !slices.Contains(lru.Order(), 0) {
+		t.Fatalf("Order() = %v, lost the pinned key", lru.Order())
+	}
+	lm.Unpin(0)
+	Must(lm.Get(3, fetch))
+	if _, ok := lm.GetIfPresent(0); ok {
+		t.Fatal("Expected the unpinned key to be the LRU victim")
+	}
+
+	lfu := lazy.NewLFUEvictionPolicy[int, int]()
+	lm = lazy.NewLazyMap[int, int](lazy.MaxSize[int, int](2), lazy.WithEvictionPolicy[int, int](lfu))
+	lm.Pin(0)
+	Must(lm.Get(0, fetch))
+	for i := 1; i <= 3; i++ {
+		Must(lm.Get(i, fetch))
+	}
+	if _, ok := lfu.Frequencies()[0]; !ok {
+		t.Fatalf("Frequencies() = %v, lost the pinned key", lfu.Frequencies())
+	}
+}
@@ -2,7 +2,11 @@ package lazy
 
 import (
 	"container/list"
+	"maps"
+	"math"
+	"math/rand/v2"
 	"sync"
+	"time"
 )
 
 // EvictionPolicy defines the strategy for removing items when the map reaches MaxSize.
@@ -17,6 +21,31 @@ type EvictionPolicy[K comparable, V any] interface {
 	SelectVictim(m map[K]*Value[V]) (K, bool)
 }
 
+// RemovalAwareEvictionPolicy is an optional interface for policies that keep per-key state.
+// Remove is called after a key has been removed from the map other than by eviction
+// (e.g. Clear or RemoveWhere), so the policy can drop its bookkeeping for it.
+// Like Access, it is called outside the map mutex.
+type RemovalAwareEvictionPolicy[K comparable, V any] interface {
+	EvictionPolicy[K, V]
+	Remove(key K)
+}
+
+// InsertAwareEvictionPolicy is an optional interface for policies that treat new keys
+// differently from accesses. Insert is called once when a key is added to the map, before the
+// Access for the same lookup; Access is still called on every access, including the first.
+// Keys reloaded in place, e.g. after expiring, aren't inserted again.
+type InsertAwareEvictionPolicy[K comparable, V any] interface {
+	EvictionPolicy[K, V]
+	Insert(key K)
+}
+
+// insert tells policy that key has just been added to the map, if it wants to know.
+func insert[K comparable, V any](policy EvictionPolicy[K, V], key K) {
+	if p, ok := policy.(InsertAwareEvictionPolicy[K, V]); ok {
+		p.Insert(key)
+	}
+}
+
 // RandomEvictionPolicy implements EvictionPolicy using Go's map iteration order.
 type RandomEvictionPolicy[K comparable, V any] struct{}
 
@@ -30,6 +59,75 @@ func (p *RandomEvictionPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
 	return zero, false
 }
 
+// SeededRandomEvictionPolicy evicts a uniformly random key, drawn from a seeded random source
+// so that eviction is reproducible and doesn't depend on Go's map iteration order. It tracks
+// the keys it has seen through Access in a slice, dropping keys that have left the map as it
+// comes across them.
+type SeededRandomEvictionPolicy[K comparable, V any] struct {
+	mu    sync.Mutex
+	rng   *rand.Rand
+	keys  []K
+	index map[K]int
+}
+
+// NewSeededRandomEvictionPolicy creates a SeededRandomEvictionPolicy whose choices are
+// determined by seed and the order in which keys are accessed.
+func NewSeededRandomEvictionPolicy[K comparable, V any](seed int64) *SeededRandomEvictionPolicy[K, V] {
+	return &SeededRandomEvictionPolicy[K, V]{
+		rng:   rand.New(rand.NewPCG(uint64(seed), 0)),
+		index: make(map[K]int),
+	}
+}
+
+func (p *SeededRandomEvictionPolicy[K, V]) Access(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.index[key]; ok {
+		return
+	}
+	p.index[key] = len(p.keys)
+	p.keys = append(p.keys, key)
+}
+
+func (p *SeededRandomEvictionPolicy[K, V]) Remove(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.remove(key)
+}
+
+// remove drops key from tracking by moving the last key into its slot.
+func (p *SeededRandomEvictionPolicy[K, V]) remove(key K) {
+	i, ok := p.index[key]
+	if !ok {
+		return
+	}
+	last := len(p.keys) - 1
+	p.keys[i] = p.keys[last]
+	p.index[p.keys[i]] = i
+	p.keys = p.keys[:last]
+	delete(p.index, key)
+}
+
+func (p *SeededRandomEvictionPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.keys) > 0 {
+		key := p.keys[p.rng.IntN(len(p.keys))]
+		p.remove(key)
+		// Skip keys no longer in the map (e.g. deleted externally).
+		if _, ok := m[key]; ok {
+			return key, true
+		}
+	}
+
+	for k := range m {
+		return k, true
+	}
+	var zero K
+	return zero, false
+}
+
 // NoEvictionPolicy is a no-op policy.
 type NoEvictionPolicy[K comparable, V any] struct{}
 
@@ -65,6 +163,22 @@ func (p *LRUEvictionPolicy[K, V]) Access(key K) {
 	p.items[key] = elem
 }
 
+func (p *LRUEvictionPolicy[K, V]) Remove(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if elem, ok := p.items[key]; ok {
+		p.queue.Remove(elem)
+		delete(p.items, key)
+	}
+}
+
+// Order returns the tracked keys from most to least recently used, for debugging.
+func (p *LRUEvictionPolicy[K, V]) Order() []K {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return listKeys[K](p.queue)
+}
+
 func (p *LRUEvictionPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -94,6 +208,15 @@ func (p *LRUEvictionPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
 	return zero, false
 }
 
+// listKeys returns the keys held in l, front to back.
+func listKeys[K any](l *list.List) []K {
+	keys := make([]K, 0, l.Len())
+	for e := l.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(K))
+	}
+	return keys
+}
+
 // FIFOEvictionPolicy implements First-In-First-Out eviction.
 type FIFOEvictionPolicy[K comparable, V any] struct {
 	mu    sync.Mutex
@@ -108,14 +231,44 @@ func NewFIFOEvictionPolicy[K comparable, V any]() *FIFOEvictionPolicy[K, V] {
 	}
 }
 
+// Insert queues key as the newest entry. A key that is still tracked, because it left the map
+// without the policy being told, moves to the back of the queue.
+func (p *FIFOEvictionPolicy[K, V]) Insert(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if elem, ok := p.items[key]; ok {
+		p.queue.MoveToBack(elem)
+		return
+	}
+	p.items[key] = p.queue.PushBack(key)
+}
+
+// Access doesn't change the order. It only queues keys the policy hasn't seen, for callers
+// that don't report inserts.
 func (p *FIFOEvictionPolicy[K, V]) Access(key K) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	if _, ok := p.items[key]; ok {
 		return
 	}
-	elem := p.queue.PushBack(key)
-	p.items[key] = elem
+	p.items[key] = p.queue.PushBack(key)
+}
+
+func (p *FIFOEvictionPolicy[K, V]) Remove(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if elem, ok := p.items[key]; ok {
+		p.queue.Remove(elem)
+		delete(p.items, key)
+	}
+}
+
+// Order returns the tracked keys in insertion order, oldest (next to be evicted) first,
+// for debugging.
+func (p *FIFOEvictionPolicy[K, V]) Order() []K {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return listKeys[K](p.queue)
 }
 
 func (p *FIFOEvictionPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
@@ -162,6 +315,19 @@ func (p *LFUEvictionPolicy[K, V]) Access(key K) {
 	p.freqs[key]++
 }
 
+func (p *LFUEvictionPolicy[K, V]) Remove(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.freqs, key)
+}
+
+// Frequencies returns a copy of the access count of each tracked key, for debugging.
+func (p *LFUEvictionPolicy[K, V]) Frequencies() map[K]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.freqs)
+}
+
 func (p *LFUEvictionPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -194,3 +360,235 @@ func (p *LFUEvictionPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
 	var zero K
 	return zero, false
 }
+
+// WindowedLFUEvictionPolicy implements Least Frequently Used eviction with frequencies that
+// decay over time, so keys that were popular long ago don't outlive keys popular now.
+// Each key's count halves for every halfLife that passes without it being accessed.
+type WindowedLFUEvictionPolicy[K comparable, V any] struct {
+	mu       sync.Mutex
+	halfLife time.Duration
+	freqs    map[K]decayedCount
+}
+
+// decayedCount is a frequency count as of lastAccess.
+type decayedCount struct {
+	count      float64
+	lastAccess time.Time
+}
+
+// NewWindowedLFUEvictionPolicy creates a WindowedLFUEvictionPolicy whose counts halve every
+// halfLife. A halfLife of zero or less disables decay, behaving like LFUEvictionPolicy.
+func NewWindowedLFUEvictionPolicy[K comparable, V any](halfLife time.Duration) *WindowedLFUEvictionPolicy[K, V] {
+	return &WindowedLFUEvictionPolicy[K, V]{
+		halfLife: halfLife,
+		freqs:    make(map[K]decayedCount),
+	}
+}
+
+// decayed returns the value of c at now.
+func (p *WindowedLFUEvictionPolicy[K, V]) decayed(c decayedCount, now time.Time) float64 {
+	if p.halfLife <= 0 {
+		return c.count
+	}
+	return c.count * math.Exp2(-float64(now.Sub(c.lastAccess))/float64(p.halfLife))
+}
+
+func (p *WindowedLFUEvictionPolicy[K, V]) Access(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	p.freqs[key] = decayedCount{count: p.decayed(p.freqs[key], now) + 1, lastAccess: now}
+}
+
+func (p *WindowedLFUEvictionPolicy[K, V]) Remove(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.freqs, key)
+}
+
+func (p *WindowedLFUEvictionPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var victim K
+	minScore := 0.0
+	found := false
+	for k := range m {
+		// Keys never accessed score zero.
+		score := p.decayed(p.freqs[k], now)
+		if !found || score < minScore {
+			minScore = score
+			victim = k
+			found = true
+		}
+	}
+	if found {
+		delete(p.freqs, victim)
+		return victim, true
+	}
+	var zero K
+	return zero, false
+}
+
+// SLRUEvictionPolicy implements Segmented LRU eviction.
+// New keys enter a probationary segment and are promoted to a protected segment when accessed
+// again. Victims are taken from the probationary segment first, so a scan of keys that are only
+// read once can't push out keys that are used repeatedly.
+type SLRUEvictionPolicy[K comparable, V any] struct {
+	mu                sync.Mutex
+	probationFraction float64
+	probation         *list.List
+	protected         *list.List
+	items             map[K]slruItem
+}
+
+// slruItem records where a key is held in an SLRUEvictionPolicy.
+type slruItem struct {
+	elem      *list.Element
+	protected bool
+}
+
+// NewSLRUEvictionPolicy creates an SLRUEvictionPolicy where roughly probationFraction of the
+// tracked keys are kept in the probationary segment; once the protected segment grows past the
+// rest, its least recently used keys are demoted back to probation.
+// Fractions outside (0, 1) default to 0.2.
+func NewSLRUEvictionPolicy[K comparable, V any](probationFraction float64) *SLRUEvictionPolicy[K, V] {
+	if probationFraction <= 0 || probationFraction >= 1 {
+		probationFraction = 0.2
+	}
+	return &SLRUEvictionPolicy[K, V]{
+		probationFraction: probationFraction,
+		probation:         list.New(),
+		protected:         list.New(),
+		items:             make(map[K]slruItem),
+	}
+}
+
+func (p *SLRUEvictionPolicy[K, V]) Access(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	item, ok := p.items[key]
+	switch {
+	case !ok:
+		p.items[key] = slruItem{elem: p.probation.PushFront(key)}
+	case item.protected:
+		p.protected.MoveToFront(item.elem)
+	default:
+		p.probation.Remove(item.elem)
+		p.items[key] = slruItem{elem: p.protected.PushFront(key), protected: true}
+		p.demote()
+	}
+}
+
+// demote moves keys from the tail of the protected segment to probation until the protected
+// segment is within its share. It must be called with p.mu held.
+func (p *SLRUEvictionPolicy[K, V]) demote() {
+	total := p.probation.Len() + p.protected.Len()
+	maxProtected := max(int(float64(total)*(1-p.probationFraction)), 1)
+	for p.protected.Len() > maxProtected {
+		key := p.protected.Remove(p.protected.Back()).(K)
+		p.items[key] = slruItem{elem: p.probation.PushFront(key)}
+	}
+}
+
+func (p *SLRUEvictionPolicy[K, V]) Remove(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.remove(key)
+}
+
+func (p *SLRUEvictionPolicy[K, V]) remove(key K) {
+	item, ok := p.items[key]
+	if !ok {
+		return
+	}
+	if item.protected {
+		p.protected.Remove(item.elem)
+	} else {
+		p.probation.Remove(item.elem)
+	}
+	delete(p.items, key)
+}
+
+func (p *SLRUEvictionPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, segment := range []*list.List{p.probation, p.protected} {
+		for segment.Len() > 0 {
+			key := segment.Back().Value.(K)
+			p.remove(key)
+			// Skip keys no longer in the map (e.g. deleted externally).
+			if _, ok := m[key]; ok {
+				return key, true
+			}
+		}
+	}
+
+	for k := range m {
+		return k, true
+	}
+	var zero K
+	return zero, false
+}
+
+// CostAwareEvictionPolicy evicts the entry with the highest weight per access, in the style of
+// GreedyDual-Size-Frequency: large, rarely used entries go first, while small, frequently used
+// entries are kept. It suits caches bounded by total bytes rather than entry count, where one
+// large value can cost as much as many small hot ones. Weights are read from the cached values
+// with weigh each time a victim is selected; entries that aren't loaded yet weigh nothing.
+type CostAwareEvictionPolicy[K comparable, V any] struct {
+	mu    sync.Mutex
+	weigh func(V) int64
+	freqs map[K]int
+}
+
+// NewCostAwareEvictionPolicy creates a CostAwareEvictionPolicy using weigh to size values.
+func NewCostAwareEvictionPolicy[K comparable, V any](weigh func(V) int64) *CostAwareEvictionPolicy[K, V] {
+	return &CostAwareEvictionPolicy[K, V]{
+		weigh: weigh,
+		freqs: make(map[K]int),
+	}
+}
+
+func (p *CostAwareEvictionPolicy[K, V]) Access(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.freqs[key]++
+}
+
+func (p *CostAwareEvictionPolicy[K, V]) Remove(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.freqs, key)
+}
+
+func (p *CostAwareEvictionPolicy[K, V]) SelectVictim(m map[K]*Value[V]) (K, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var victim K
+	maxScore := -1.0
+	found := false
+	// Candidates come from m rather than freqs, so a key still tracked after leaving the map
+	// (e.g. deleted externally without Remove) is never chosen.
+	for k, lv := range m {
+		var weight int64
+		if v, ok, err := lv.Value(); ok && err == nil {
+			weight = p.weigh(v)
+		}
+		score := float64(weight) / float64(max(p.freqs[k], 1))
+		if !found || score > maxScore {
+			maxScore = score
+			victim = k
+			found = true
+		}
+	}
+	if found {
+		delete(p.freqs, victim)
+		return victim, true
+	}
+	var zero K
+	return zero, false
+}
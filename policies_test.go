@@ -2,8 +2,12 @@ package lazy_test
 
 import (
 	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	lazy "github.com/arran4/go-be-lazy"
 )
@@ -64,6 +68,61 @@ func TestFIFOEvictionPolicy(t *testing.T) {
 	}
 }
 
+// countingPolicy counts the Insert and Access calls for each key.
+type countingPolicy struct {
+	lazy.RandomEvictionPolicy[string, int]
+	mu       sync.Mutex
+	inserts  map[string]int
+	accesses map[string]int
+}
+
+func (p *countingPolicy) Insert(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inserts[key]++
+}
+
+func (p *countingPolicy) Access(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.accesses[key]++
+}
+
+func TestInsertAwareEvictionPolicy(t *testing.T) {
+	policy := &countingPolicy{inserts: map[string]int{}, accesses: map[string]int{}}
+	lm := lazy.NewLazyMap[string, int](lazy.WithEvictionPolicy[string, int](policy))
+	fetch := func(k string) (int, error) { return len(k), nil }
+
+	for i := 0; i < 3; i++ {
+		Must(lm.Get("a", fetch))
+	}
+	Must(lm.Get("bb", fetch))
+	lm.SetMany(map[string]int{"bb": 5, "ccc": 3})
+
+	if want := map[string]int{"a": 1, "bb": 1, "ccc": 1}; !reflect.DeepEqual(policy.inserts, want) {
+		t.Errorf("Expected one Insert per key %v, got %v", want, policy.inserts)
+	}
+	if want := map[string]int{"a": 3, "bb": 2, "ccc": 1}; !reflect.DeepEqual(policy.accesses, want) {
+		t.Errorf("Expected an Access per get %v, got %v", want, policy.accesses)
+	}
+}
+
+func TestFIFOEvictionPolicyReinsert(t *testing.T) {
+	fifo := lazy.NewFIFOEvictionPolicy[string, int]()
+	for _, k := range []string{"a", "b", "c"} {
+		fifo.Insert(k)
+	}
+	fifo.Access("a")
+	if got := fifo.Order(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("FIFO order after access = %v", got)
+	}
+	// a left the map without the policy hearing about it and came back.
+	fifo.Insert("a")
+	if got := fifo.Order(); !reflect.DeepEqual(got, []string{"b", "c", "a"}) {
+		t.Errorf("FIFO order after reinsert = %v", got)
+	}
+}
+
 func TestLFUEvictionPolicy(t *testing.T) {
 	m := make(map[int]*lazy.Value[int])
 	var mu sync.RWMutex
@@ -99,6 +158,149 @@ func TestLFUEvictionPolicy(t *testing.T) {
 	}
 }
 
+func TestPolicyIntrospection(t *testing.T) {
+	lru := lazy.NewLRUEvictionPolicy[string, int]()
+	fifo := lazy.NewFIFOEvictionPolicy[string, int]()
+	lfu := lazy.NewLFUEvictionPolicy[string, int]()
+	for _, k := range []string{"a", "b", "c", "a", "b", "a"} {
+		lru.Access(k)
+		fifo.Access(k)
+		lfu.Access(k)
+	}
+
+	if got := lru.Order(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("LRU order = %v", got)
+	}
+	lru.Access("c")
+	if got := lru.Order(); !reflect.DeepEqual(got, []string{"c", "a", "b"}) {
+		t.Errorf("LRU order after access = %v", got)
+	}
+	if got := fifo.Order(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("FIFO order = %v", got)
+	}
+	freqs := lfu.Frequencies()
+	if want := map[string]int{"a": 3, "b": 2, "c": 1}; !reflect.DeepEqual(freqs, want) {
+		t.Errorf("LFU frequencies = %v", freqs)
+	}
+	// The result is a copy.
+	freqs["a"] = 100
+	if lfu.Frequencies()["a"] != 3 {
+		t.Error("Frequencies exposed internal state")
+	}
+}
+
+func TestWindowedLFUEvictionPolicy(t *testing.T) {
+	m := make(map[int]*lazy.Value[int])
+	var mu sync.RWMutex
+	fetch := func(id int) (int, error) { return id, nil }
+	policy := lazy.NewWindowedLFUEvictionPolicy[int, int](10 * time.Millisecond)
+	opts := []lazy.Option[int, int]{lazy.MaxSize[int, int](2), lazy.WithEvictionPolicy[int, int](policy)}
+
+	// 1 becomes popular.
+	for i := 0; i < 20; i++ {
+		Must(lazy.Map(&m, &mu, 1, fetch, opts...))
+	}
+	Must(lazy.Map(&m, &mu, 2, fetch, opts...))
+
+	// While 1's count is fresh, 2 is the victim.
+	Must(lazy.Map(&m, &mu, 3, fetch, opts...))
+	if _, ok := m[2]; ok {
+		t.Fatal("Expected 2 to be evicted")
+	}
+	if _, ok := m[1]; !ok {
+		t.Fatal("Expected 1 to be present")
+	}
+
+	// After many half-lives 1's count has decayed, while 3 is popular now.
+	time.Sleep(200 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		Must(lazy.Map(&m, &mu, 3, fetch, opts...))
+	}
+	Must(lazy.Map(&m, &mu, 4, fetch, opts...))
+	if _, ok := m[1]; ok {
+		t.Fatal("Expected decayed 1 to be evicted")
+	}
+	if _, ok := m[3]; !ok {
+		t.Fatal("Expected 3 to be present")
+	}
+}
+
+func TestCostAwareEvictionPolicy(t *testing.T) {
+	m := make(map[string]*lazy.Value[string])
+	var mu sync.RWMutex
+	fetch := func(id string) (string, error) {
+		if id == "big" {
+			return strings.Repeat("x", 1000), nil
+		}
+		return id, nil
+	}
+	policy := lazy.NewCostAwareEvictionPolicy[string, string](func(v string) int64 { return int64(len(v)) })
+	opts := []lazy.Option[string, string]{lazy.MaxSize[string, string](2), lazy.WithEvictionPolicy[string, string](policy)}
+
+	Must(lazy.Map(&m, &mu, "big", fetch, opts...))
+	for i := 0; i < 10; i++ {
+		Must(lazy.Map(&m, &mu, "hot", fetch, opts...))
+	}
+
+	// The large, rarely used entry goes before the small, frequently used one.
+	Must(lazy.Map(&m, &mu, "new", fetch, opts...))
+	if _, ok := m["big"]; ok {
+		t.Fatal("Expected big to be evicted")
+	}
+	if _, ok := m["hot"]; !ok {
+		t.Fatal("Expected hot to be present")
+	}
+}
+
+func TestCostAwareEvictionPolicySkipsKeysGoneFromMap(t *testing.T) {
+	m := make(map[string]*lazy.Value[string])
+	var mu sync.RWMutex
+	fetch := func(id string) (string, error) { return strings.Repeat("x", len(id)*100), nil }
+	policy := lazy.NewCostAwareEvictionPolicy[string, string](func(v string) int64 { return int64(len(v)) })
+	opts := []lazy.Option[string, string]{lazy.WithEvictionPolicy[string, string](policy)}
+
+	Must(lazy.Map(&m, &mu, "biggest", fetch, opts...))
+	Must(lazy.Map(&m, &mu, "small", fetch, opts...))
+	// The policy still tracks "biggest", but it is no longer in the map.
+	delete(m, "biggest")
+
+	if victim, ok := policy.SelectVictim(m); !ok || victim != "small" {
+		t.Fatalf("SelectVictim = %q, %v; want small", victim, ok)
+	}
+}
+
+func TestSLRUEvictionPolicy(t *testing.T) {
+	m := make(map[int]*lazy.Value[int])
+	var mu sync.RWMutex
+	fetch := func(id int) (int, error) { return id, nil }
+	policy := lazy.NewSLRUEvictionPolicy[int, int](0.5)
+	opts := []lazy.Option[int, int]{lazy.MaxSize[int, int](3), lazy.WithEvictionPolicy[int, int](policy)}
+
+	// Add 1 and access it again, promoting it. Protected: [1]
+	Must(lazy.Map(&m, &mu, 1, fetch, opts...))
+	Must(lazy.Map(&m, &mu, 1, fetch, opts...))
+
+	// A scan of keys read once. Probation: [3, 2]
+	Must(lazy.Map(&m, &mu, 2, fetch, opts...))
+	Must(lazy.Map(&m, &mu, 3, fetch, opts...))
+
+	// Add 4. Plain LRU would evict 1, but SLRU takes probation's tail, 2.
+	Must(lazy.Map(&m, &mu, 4, fetch, opts...))
+	// Add 5. Evicts 3.
+	Must(lazy.Map(&m, &mu, 5, fetch, opts...))
+
+	for _, k := range []int{2, 3} {
+		if _, ok := m[k]; ok {
+			t.Fatalf("Expected %d to be evicted", k)
+		}
+	}
+	for _, k := range []int{1, 4, 5} {
+		if _, ok := m[k]; !ok {
+			t.Fatalf("Expected %d to be present", k)
+		}
+	}
+}
+
 func TestEvictionPolicyConcurrency(t *testing.T) {
 	m := make(map[int]*lazy.Value[int])
 	var mu sync.RWMutex
@@ -125,6 +327,59 @@ func TestEvictionPolicyConcurrency(t *testing.T) {
 	}
 }
 
+func TestSeededRandomEvictionPolicy(t *testing.T) {
+	victims := func(seed int64) []int {
+		m := make(map[int]*lazy.Value[int])
+		var mu sync.RWMutex
+		fetch := func(id int) (int, error) { return id, nil }
+		policy := lazy.NewSeededRandomEvictionPolicy[int, int](seed)
+		opts := []lazy.Option[int, int]{lazy.MaxSize[int, int](5), lazy.WithEvictionPolicy[int, int](policy)}
+		var evicted []int
+		for i := 0; i < 20; i++ {
+			Must(lazy.Map(&m, &mu, i, fetch, opts...))
+			for k := 0; k <= i; k++ {
+				if _, ok := m[k]; !ok && !slices.Contains(evicted, k) {
+					evicted = append(evicted, k)
+				}
+			}
+		}
+		return evicted
+	}
+
+	first := victims(42)
+	if len(first) != 15 {
+		t.Fatalf("Expected 15 evictions, got %d", len(first))
+	}
+	for i := 0; i < 3; i++ {
+		if got := victims(42); !slices.Equal(got, first) {
+			t.Fatalf("Expected the same victims for the same seed, got %v and %v", first, got)
+		}
+	}
+}
+
+func TestMapReducedMaxSize(t *testing.T) {
+	m := make(map[int]*lazy.Value[int])
+	var mu sync.RWMutex
+	fetch := func(id int) (int, error) { return id, nil }
+
+	for i := 0; i < 10; i++ {
+		Must(lazy.Map(&m, &mu, i, fetch, lazy.MaxSize[int, int](10)))
+	}
+	Must(lazy.Map(&m, &mu, 10, fetch, lazy.MaxSize[int, int](3), lazy.WithEvictionPolicy[int, int](lazy.NewLRUEvictionPolicy[int, int]())))
+	if len(m) != 3 {
+		t.Fatalf("Expected the map to shrink to 3, got %d", len(m))
+	}
+	if _, ok := m[10]; !ok {
+		t.Fatal("Expected the inserted key to be kept")
+	}
+
+	// A policy that never picks a victim leaves the map as it is.
+	Must(lazy.Map(&m, &mu, 11, fetch, lazy.MaxSize[int, int](1), lazy.WithEvictionPolicy[int, int](&lazy.NoEvictionPolicy[int, int]{})))
+	if len(m) != 4 {
+		t.Fatalf("Expected no eviction with NoEvictionPolicy, got size %d", len(m))
+	}
+}
+
 func TestNoEvictionPolicy(t *testing.T) {
 	m := make(map[int]*lazy.Value[int])
 	var mu sync.RWMutex
@@ -140,3 +395,101 @@ func TestNoEvictionPolicy(t *testing.T) {
 		t.Fatalf("Expected map size 3 (no eviction), got %d", len(m))
 	}
 }
+
+// blockingEvictionPolicy records the map size seen by SelectVictim and blocks until released.
+type blockingEvictionPolicy struct {
+	lazy.RandomEvictionPolicy[int, int]
+	release chan struct{}
+	seen    chan int
+}
+
+func (p *blockingEvictionPolicy) SelectVictim(m map[int]*lazy.Value[int]) (int, bool) {
+	select {
+	case p.seen <- len(m):
+	default:
+	}
+	<-p.release
+	return p.RandomEvictionPolicy.SelectVictim(m)
+}
+
+func TestAsyncEviction(t *testing.T) {
+	policy := &blockingEvictionPolicy{release: make(chan struct{}), seen: make(chan int, 1)}
+	m := make(map[int]*lazy.Value[int])
+	var mu sync.RWMutex
+	// The same options are reused across calls so pending evictions are coalesced.
+	opts := []lazy.Option[int, int]{
+		lazy.MaxSize[int, int](3),
+		lazy.WithEvictionPolicy[int, int](policy),
+		lazy.WithAsyncEviction[int, int](),
+	}
+	fetch := func(id int) (int, error) { return id, nil }
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 4; i++ {
+			if _, err := lazy.Map(&m, &mu, i, fetch, opts...); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("insert blocked on eviction")
+	}
+
+	if size := <-policy.seen; size != 4 {
+		t.Fatalf("expected evictor to see overshoot of 4 entries, got %d", size)
+	}
+	close(policy.release)
+
+	size := func() int {
+		mu.RLock()
+		defer mu.RUnlock()
+		return len(m)
+	}
+	deadline := time.Now().Add(time.Second)
+	for size() != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("map not trimmed, size %d", size())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWithEvictionPolicyFor(t *testing.T) {
+	// Even keys use LRU, odd keys FIFO.
+	lru := lazy.NewLRUEvictionPolicy[int, int]()
+	fifo := lazy.NewFIFOEvictionPolicy[int, int]()
+	lm := lazy.NewLazyMap[int, int](
+		lazy.MaxSize[int, int](4),
+		lazy.WithEvictionPolicyFor[int, int](func(k int) lazy.EvictionPolicy[int, int] {
+			if k%2 == 0 {
+				return lru
+			}
+			return fifo
+		}),
+	)
+	fetch := func(k int) (int, error) { return k, nil }
+	for _, k := range []int{2, 4, 1, 3} {
+		Must(lm.Get(k, fetch))
+	}
+	// Touch the oldest key of each group: LRU keeps 2, FIFO still evicts 1.
+	Must(lm.Get(2, fetch))
+	Must(lm.Get(1, fetch))
+
+	Must(lm.Get(6, fetch)) // Evicts from the even group: 4 is least recently used.
+	Must(lm.Get(5, fetch)) // Evicts from the odd group: 1 was first in.
+
+	for _, k := range []int{4, 1} {
+		if _, ok := lm.GetIfPresent(k); ok {
+			t.Fatalf("Expected %d to be evicted", k)
+		}
+	}
+	for _, k := range []int{2, 6, 3, 5} {
+		if _, ok := lm.GetIfPresent(k); !ok {
+			t.Fatalf("Expected %d to be present", k)
+		}
+	}
+}
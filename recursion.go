@@ -0,0 +1,142 @@
+package lazy
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// ErrRecursiveLoad is returned under WithRecursionGuard when a fetch function loads the very
+// key it is fetching, which could otherwise never complete.
+var ErrRecursiveLoad = errors.New("lazy: recursive load of the same key")
+
+// WithRecursionGuard returns an Option under which a fetch that loads its own key gets
+// ErrRecursiveLoad instead of deadlocking, as does one that sets it with Value.Set or similar.
+// With WithStripedLocks, a fetch loading another key that shares its stripe loads it inline
+// rather than deadlocking too. Without the guard, loading other keys from a fetch is still
+// fine. The guard records which goroutine runs each load, which costs more than a cheap fetch,
+// so it is off by default. It applies to entries created while it is in effect.
+func WithRecursionGuard[K comparable, V any]() Option[K, V] {
+	return func(a *args[K, V]) { a.recursionGuard = true }
+}
+
+// loadLocks are the locks a Value loads under. mu is held while loading or changing the result,
+// and readyMu guards the Value's ready channel and shared load. A Value has locks of its own
+// unless its map was created with WithStripedLocks, in which case it shares them with the
+// other keys of its stripe.
+type loadLocks struct {
+	mu      sync.Mutex
+	readyMu sync.Mutex
+}
+
+// loadOwners maps each *loadLocks whose mu is held for a guarded load to the ID of the
+// goroutine holding it, so that a fetch loading its own key, or another key in the same
+// stripe, can be detected; see lockLoad. It is kept apart from loadLocks so that maps without
+// WithRecursionGuard don't pay for it in every entry.
+var loadOwners sync.Map
+
+// heldByCaller reports whether the calling goroutine holds lk.mu for a guarded load.
+func (lk *loadLocks) heldByCaller() bool {
+	owner, ok := loadOwners.Load(lk)
+	return ok && owner.(int64) == goid()
+}
+
+// ownedValue is a Value allocated together with its own locks, saving a second allocation.
+type ownedValue[T any] struct {
+	v   Value[T]
+	own loadLocks
+}
+
+// lk returns the Value's locks, allocating them on first use for a Value that wasn't created
+// with any, such as a zero Value.
+func (l *Value[T]) lk() *loadLocks {
+	if lk := l.locks.Load(); lk != nil {
+		return lk
+	}
+	l.locks.CompareAndSwap(nil, new(loadLocks))
+	return l.locks.Load()
+}
+
+// lockLoad acquires the load lock. Under WithRecursionGuard it also records the calling
+// goroutine as its owner: if that goroutine already holds the lock, because a fetch is loading
+// its own key, it returns ErrRecursiveLoad instead of deadlocking, and if it holds it for
+// another key sharing the stripe, it carries on under that hold and reports held, which must be
+// passed to unlockLoad. Recording the owner costs a goroutine ID lookup, which is why the guard
+// is opt-in: it can't be left until there is contention, as the recursive call is the contention.
+func (l *Value[T]) lockLoad() (held bool, err error) {
+	lk := l.lk()
+	if !l.has(flagGuarded) {
+		lk.mu.Lock()
+		return false, nil
+	}
+	if !lk.mu.TryLock() {
+		if lk.heldByCaller() {
+			if !l.mark(flagInLoad) {
+				return false, ErrRecursiveLoad
+			}
+			return true, nil
+		}
+		lk.mu.Lock()
+	}
+	loadOwners.Store(lk, goid())
+	l.mark(flagInLoad)
+	return false, nil
+}
+
+// unlockLoad releases the load lock after lockLoad.
+func (l *Value[T]) unlockLoad(held bool) {
+	lk := l.lk()
+	if !l.has(flagGuarded) {
+		lk.mu.Unlock()
+		return
+	}
+	l.unmark(flagInLoad)
+	if held {
+		return
+	}
+	loadOwners.Delete(lk)
+	lk.mu.Unlock()
+}
+
+// lockWrite acquires the load lock to change the result outside of a load. Like lockLoad under
+// WithRecursionGuard, it reports held if the calling goroutine already holds it, which must be
+// passed to unlockWrite.
+func (l *Value[T]) lockWrite() (held bool) {
+	lk := l.lk()
+	if lk.mu.TryLock() {
+		return false
+	}
+	if l.has(flagGuarded) && lk.heldByCaller() {
+		return true
+	}
+	lk.mu.Lock()
+	return false
+}
+
+// unlockWrite releases the load lock after lockWrite.
+func (l *Value[T]) unlockWrite(held bool) {
+	if !held {
+		l.lk().mu.Unlock()
+	}
+}
+
+// loading reports whether the calling goroutine is running a load of l. It is only known
+// under WithRecursionGuard; otherwise it reports false.
+func (l *Value[T]) loading() bool {
+	return l.has(flagGuarded) && l.has(flagInLoad) && l.lk().heldByCaller()
+}
+
+// goid returns the ID of the calling goroutine, parsed from its stack trace header
+// ("goroutine 123 [running]:").
+func goid() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseInt(string(b), 10, 64)
+	return id
+}
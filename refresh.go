@@ -0,0 +1,126 @@
+package lazy
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrRefreshAheadNeedsDeadline is returned by Map when WithRefreshAhead is used without a
+// time-based Expiry such as ExpireAfter or ExpireAt.
+var ErrRefreshAheadNeedsDeadline = errors.New("refresh ahead requires a time-based expiry")
+
+// WithRefreshAhead returns an Option that reloads a value in the background once it is within
+// lead of expiring. The read that notices this still returns the cached value, so hot keys don't
+// stall when they expire. At most one background refresh runs per entry; if it fails the cached
+// value is kept until it expires normally. A refresh that doesn't push the deadline back, such
+// as under ExpireAt, happens only once, as another would gain nothing.
+// It requires a time-based Expiry (ExpireAfter, ExpireAt, or a combination including one);
+// otherwise Map returns ErrRefreshAheadNeedsDeadline.
+func WithRefreshAhead[K comparable, V any](lead time.Duration) Option[K, V] {
+	return func(a *args[K, V]) { a.refreshAhead = lead }
+}
+
+// WithRefreshConcurrency returns an Option that allows at most n background refreshes (see
+// WithRefreshAhead) to run at once, so that many keys nearing expiry together don't overwhelm
+// the backend. By default excess refreshes wait for a slot; with SkipBusyRefreshes they are
+// skipped instead and the cached value keeps being served, to be refreshed by a later read.
+// The limit is held by the Option, so it is shared by every call the Option is passed to,
+// e.g. every Get on a LazyMap created with it.
+func WithRefreshConcurrency[K comparable, V any](n int) Option[K, V] {
+	sem := make(chan struct{}, max(n, 1))
+	return func(a *args[K, V]) { a.refreshSem = sem }
+}
+
+// SkipBusyRefreshes returns an Option that skips a background refresh, rather than waiting,
+// when WithRefreshConcurrency's limit has been reached.
+func SkipBusyRefreshes[K comparable, V any]() Option[K, V] {
+	return func(a *args[K, V]) { a.skipBusyRefresh = true }
+}
+
+// checkRefreshAhead reports whether the configuration can support WithRefreshAhead.
+func (a *args[K, V]) checkRefreshAhead() error {
+	if a.refreshAhead <= 0 {
+		return nil
+	}
+	if !timeBased(a.expiry) {
+		return ErrRefreshAheadNeedsDeadline
+	}
+	return nil
+}
+
+// timeBased reports whether e gives values a deadline. ExpireWhenAll and ExpireWhenAny always
+// implement deadlineExpiry, so they are judged by their policies instead: all of them, or any
+// of them, must be time-based.
+func timeBased[V any](e Expiry[V]) bool {
+	switch e := e.(type) {
+	case *expireWhenAll[V]:
+		return len(e.policies) > 0 && !slices.ContainsFunc(e.policies, func(p Expiry[V]) bool { return !timeBased(p) })
+	case *expireWhenAny[V]:
+		return slices.ContainsFunc(e.policies, timeBased[V])
+	}
+	_, ok := e.(deadlineExpiry[V])
+	return ok
+}
+
+// maybeRefreshAhead starts a background refresh of lv if it is close to its deadline.
+func maybeRefreshAhead[K comparable, V any](m *map[K]*Value[V], mu *sync.RWMutex, id K, lv *Value[V], fetch func(K) (V, error), a *args[K, V]) {
+	if a.refreshAhead <= 0 || fetch == nil {
+		return
+	}
+	de, ok := a.expiry.(deadlineExpiry[V])
+	if !ok {
+		return
+	}
+	deadline, ok := de.deadline(lv)
+	if !ok || deadline.Sub(lv.now()) > a.refreshAhead {
+		return
+	}
+	if !lv.mark(flagRefreshing) {
+		return
+	}
+	if a.refreshSem != nil && a.skipBusyRefresh {
+		select {
+		case a.refreshSem <- struct{}{}:
+		default:
+			lv.unmark(flagRefreshing)
+			return
+		}
+	}
+	go func() {
+		if a.refreshSem != nil {
+			if !a.skipBusyRefresh {
+				a.refreshSem <- struct{}{}
+			}
+			defer func() { <-a.refreshSem }()
+		}
+		fresh := a.newValue(id)
+		if _, err := fresh.Load(a.publishing(id, a.timed(id, a.recovering(func() (V, error) { return fetch(id) })))); err != nil {
+			// Keep serving the current value until it expires; a later read may try again.
+			lv.unmark(flagRefreshing)
+			return
+		}
+		if d, ok := de.deadline(fresh); !ok || !d.After(deadline) {
+			// Refreshing didn't move the deadline, as with ExpireAt, so neither would refreshing
+			// again: let the fresh value expire normally rather than refresh on every read.
+			fresh.mark(flagRefreshing)
+		}
+		// Only replace the entry we refreshed; it may have been removed or replaced meanwhile.
+		swapIn(m, mu, id, lv, fresh, a)
+	}()
+}
+
+// swapIn replaces previous with lv as the entry for id, provided previous is still the entry held.
+func swapIn[K comparable, V any](m *map[K]*Value[V], mu *sync.RWMutex, id K, previous, lv *Value[V], a *args[K, V]) {
+	var removals []removal[K, V]
+	mu.Lock()
+	if (*m)[id] == previous {
+		lv.markIf(flagPinned, previous.has(flagPinned))
+		(*m)[id] = lv
+		removals = append(removals, removal[K, V]{key: id, value: previous, reason: RemovalSwapped})
+		a.republish(*m)
+	}
+	mu.Unlock()
+	a.removed(removals)
+}
@@ -0,0 +1,257 @@
+package lazy_test
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestRefreshAhead(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int64](
+		lazy.WithExpiry[string, int64](lazy.ExpireAfter[int64](500*time.Millisecond)),
+		lazy.WithRefreshAhead[string, int64](400*time.Millisecond),
+	)
+	var calls atomic.Int64
+	fetch := func(string) (int64, error) { return calls.Add(1), nil }
+
+	start := time.Now()
+	if v := Must(lm.Get("k", fetch)); v != 1 {
+		t.Fatalf("first got %d", v)
+	}
+
+	// Still fresh but within the lead: served from cache while a refresh starts.
+	time.Sleep(150 * time.Millisecond)
+	if v := Must(lm.Get("k", fetch)); v != 1 {
+		t.Fatalf("lead read got %d", v)
+	}
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("background refresh did not run")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// The refreshed value replaces the entry before the original would have expired.
+	for {
+		v := Must(lm.Get("k", fetch))
+		if v == 2 {
+			break
+		}
+		if time.Since(start) > 500*time.Millisecond {
+			t.Fatalf("value not refreshed before expiry, got %d", v)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("refresh happened after the original expiry")
+	}
+}
+
+func TestWithRefreshConcurrency(t *testing.T) {
+	const keys, limit = 10, 2
+	run := func(t *testing.T, extra ...lazy.Option[int, int]) (refreshes, maxRunning int32) {
+		opts := append([]lazy.Option[int, int]{
+			lazy.WithExpiry[int, int](lazy.ExpireAfter[int](time.Second)),
+			lazy.WithRefreshAhead[int, int](990 * time.Millisecond),
+			lazy.WithRefreshConcurrency[int, int](limit),
+		}, extra...)
+		lm := lazy.NewLazyMap[int, int](opts...)
+		for k := 0; k < keys; k++ {
+			Must(lm.Get(k, func(k int) (int, error) { return k, nil }))
+		}
+
+		var count, running, peak atomic.Int32
+		refresh := func(k int) (int, error) {
+			count.Add(1)
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			running.Add(-1)
+			return k + 100, nil
+		}
+		// Every key is now within the lead, so each read starts a background refresh.
+		time.Sleep(20 * time.Millisecond)
+		for k := 0; k < keys; k++ {
+			Must(lm.Get(k, refresh))
+		}
+		// Wait for the refreshes to settle.
+		time.Sleep(300 * time.Millisecond)
+		return count.Load(), peak.Load()
+	}
+
+	t.Run("wait", func(t *testing.T) {
+		refreshes, peak := run(t)
+		if refreshes != keys {
+			t.Fatalf("refreshes = %d, want %d", refreshes, keys)
+		}
+		if peak > limit {
+			t.Fatalf("%d refreshes ran at once, limit %d", peak, limit)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		refreshes, peak := run(t, lazy.SkipBusyRefreshes[int, int]())
+		if refreshes != limit {
+			t.Fatalf("refreshes = %d, want %d", refreshes, limit)
+		}
+		if peak > limit {
+			t.Fatalf("%d refreshes ran at once, limit %d", peak, limit)
+		}
+	})
+}
+
+func TestRefreshAheadNeedsDeadline(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](
+		lazy.WithExpiry[string, int](lazy.ExpireAfterUses[int](5)),
+		lazy.WithRefreshAhead[string, int](time.Second),
+	)
+	_, err := lm.Get("k", func(string) (int, error) { return 1, nil })
+	if !errors.Is(err, lazy.ErrRefreshAheadNeedsDeadline) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestRefreshAheadNeedsDeadlineInComposite(t *testing.T) {
+	for name, e := range map[string]lazy.Expiry[int]{
+		"any": lazy.ExpireWhenAny(lazy.ExpireAfterUses[int](5)),
+		"all": lazy.ExpireWhenAll(lazy.ExpireAfter[int](time.Minute), lazy.ExpireAfterUses[int](5)),
+	} {
+		lm := lazy.NewLazyMap[string, int](
+			lazy.WithExpiry[string, int](e),
+			lazy.WithRefreshAhead[string, int](time.Second),
+		)
+		if _, err := lm.Get("k", func(string) (int, error) { return 1, nil }); !errors.Is(err, lazy.ErrRefreshAheadNeedsDeadline) {
+			t.Fatalf("%s: err=%v", name, err)
+		}
+	}
+}
+
+func TestRefreshAheadAbsoluteDeadline(t *testing.T) {
+	clock := lazy.NewFakeClock(time.Now())
+	lm := lazy.NewLazyMap[string, int64](
+		lazy.WithTimeSource[string, int64](clock),
+		lazy.WithExpiry[string, int64](lazy.ExpireAt[int64](clock.Now().Add(10*time.Second))),
+		lazy.WithRefreshAhead[string, int64](5*time.Second),
+	)
+	var calls atomic.Int64
+	fetched := make(chan struct{}, 10)
+	fetch := func(string) (int64, error) {
+		defer func() { fetched <- struct{}{} }()
+		return calls.Add(1), nil
+	}
+
+	Must(lm.Get("k", fetch))
+	<-fetched
+	clock.Advance(6 * time.Second)
+	Must(lm.Get("k", fetch))
+	select {
+	case <-fetched:
+	case <-time.After(time.Second):
+		t.Fatal("background refresh did not run")
+	}
+	deadline := time.Now().Add(time.Second)
+	for Must(lm.Get("k", fetch)) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("refreshed value was not swapped in")
+		}
+		runtime.Gosched()
+	}
+	// The refreshed value has the same deadline, so reading it mustn't refresh again.
+	for i := 0; i < 20; i++ {
+		Must(lm.Get("k", fetch))
+	}
+	select {
+	case <-fetched:
+		t.Fatalf("Expected a single refresh ahead of an absolute deadline, got %d fetches", calls.Load())
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestKeepOnRefreshError(t *testing.T) {
+	m := make(map[string]*lazy.Value[int])
+	var mu sync.RWMutex
+	fail := false
+	fetch := func(string) (int, error) {
+		if fail {
+			return 0, errors.New("backend blip")
+		}
+		return 10, nil
+	}
+	opts := []lazy.Option[string, int]{
+		lazy.DefaultValue[string, int](0),
+		lazy.WithKeepOnRefreshError[string, int](),
+	}
+
+	if v := Must(lazy.Map(&m, &mu, "k", fetch, opts...)); v != 10 {
+		t.Fatalf("first got %d", v)
+	}
+	fail = true
+	refresh := append(opts, lazy.Refresh[string, int]())
+	if v, err := lazy.Map(&m, &mu, "k", fetch, refresh...); err != nil || v != 10 {
+		t.Fatalf("failed refresh got %v %v", v, err)
+	}
+	if v := Must(lazy.Map(&m, &mu, "k", fetch, opts...)); v != 10 {
+		t.Fatalf("after failed refresh got %d", v)
+	}
+
+	// The same holds when refreshing in place.
+	inPlace := append(refresh, lazy.WithEqual[string, int](nil))
+	if v, err := lazy.Map(&m, &mu, "k", fetch, inPlace...); err != nil || v != 10 {
+		t.Fatalf("failed in place refresh got %v %v", v, err)
+	}
+
+	// A successful refresh still replaces the value.
+	fail = false
+	if v := Must(lazy.Map(&m, &mu, "k", func(string) (int, error) { return 11, nil }, refresh...)); v != 11 {
+		t.Fatalf("refresh got %d", v)
+	}
+	if v := Must(lazy.Map(&m, &mu, "k", fetch, opts...)); v != 11 {
+		t.Fatalf("after refresh got %d", v)
+	}
+}
+
+func TestWithForceFetch(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	Must(lm.Get("k", func(string) (int, error) { return 1, nil }))
+
+	t.Run("success updates", func(t *testing.T) {
+		v, err := lm.Get("k", func(string) (int, error) { return 2, nil }, lazy.WithForceFetch[string, int]())
+		if err != nil || v != 2 {
+			t.Fatalf("got %v %v", v, err)
+		}
+		if v, ok := lm.GetIfPresent("k"); !ok || v != 2 {
+			t.Fatalf("cached %v %v", v, ok)
+		}
+	})
+
+	t.Run("failure retains old value", func(t *testing.T) {
+		v, err := lm.Get("k", func(string) (int, error) { return 0, errors.New("down") }, lazy.WithForceFetch[string, int]())
+		if err != nil || v != 2 {
+			t.Fatalf("got %v %v", v, err)
+		}
+		if v, ok := lm.GetIfPresent("k"); !ok || v != 2 {
+			t.Fatalf("cached %v %v", v, ok)
+		}
+	})
+}
+
+func TestRefreshErrorWithoutKeepUsesDefault(t *testing.T) {
+	m := make(map[string]*lazy.Value[int])
+	var mu sync.RWMutex
+	Must(lazy.Map(&m, &mu, "k", func(string) (int, error) { return 10, nil }))
+	v, err := lazy.Map(&m, &mu, "k", func(string) (int, error) { return 0, errors.New("blip") },
+		lazy.DefaultValue[string, int](0), lazy.Refresh[string, int]())
+	if err != nil || v != 0 {
+		t.Fatalf("got %v %v", v, err)
+	}
+}
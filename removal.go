@@ -0,0 +1,310 @@
+package lazy
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// RemovalReason describes why an entry left the map.
+type RemovalReason int
+
+const (
+	// RemovalEvicted means the entry was evicted to keep the map within MaxSize.
+	RemovalEvicted RemovalReason = iota + 1
+	// RemovalExpired means the entry's Expiry policy reported it as expired.
+	RemovalExpired
+	// RemovalCleared means the entry was removed explicitly, e.g. via Clear or Remove.
+	RemovalCleared
+	// RemovalSwapped means the entry was replaced by a new one, e.g. via Refresh.
+	RemovalSwapped
+	// RemovalReleased means the entry was loaded by GetContext and its context has ended.
+	RemovalReleased
+	// RemovalInvalidatedAll means the entry was invalidated by LazyMap.Bump.
+	RemovalInvalidatedAll
+)
+
+func (r RemovalReason) String() string {
+	switch r {
+	case RemovalEvicted:
+		return "evicted"
+	case RemovalExpired:
+		return "expired"
+	case RemovalCleared:
+		return "cleared"
+	case RemovalSwapped:
+		return "swapped"
+	case RemovalReleased:
+		return "released"
+	case RemovalInvalidatedAll:
+		return "invalidated"
+	}
+	return "unknown"
+}
+
+// expired reports whether r is one of the ways an entry expires. Released and invalidated
+// entries count as expired for stats, metrics, events and WithExpiryCallbackCtx.
+func (r RemovalReason) expired() bool {
+	return r == RemovalExpired || r == RemovalReleased || r == RemovalInvalidatedAll
+}
+
+// expiredBy returns the RemovalReason for an entry that expired for why, as returned by
+// args.expiryReason.
+func expiredBy(why string) RemovalReason {
+	switch why {
+	case "context":
+		return RemovalReleased
+	case "bumped":
+		return RemovalInvalidatedAll
+	}
+	return RemovalExpired
+}
+
+// Removal records an entry leaving the map.
+type Removal[K comparable] struct {
+	Key    K
+	Reason RemovalReason
+	Time   time.Time
+}
+
+// removal is an entry that left the map, reported once the map lock has been released.
+type removal[K comparable, V any] struct {
+	key    K
+	value  *Value[V]
+	reason RemovalReason
+	// why is the expiry reason of an expired entry; see ExpiryReason.
+	why string
+}
+
+// removed reports entries that have left the map to the configured hooks.
+// It must be called without the map lock held.
+func (a *args[K, V]) removed(rs []removal[K, V]) {
+	for _, r := range rs {
+		if a.removalLog != nil {
+			a.removalLog.record(r.key, r.reason, a.now())
+		}
+		if a.stats != nil {
+			switch {
+			case r.reason == RemovalEvicted:
+				a.stats.evictions.Add(1)
+			case r.reason.expired():
+				a.stats.expirations.Add(1)
+			}
+		}
+		if a.metrics != nil {
+			switch {
+			case r.reason == RemovalEvicted:
+				a.metrics.OnEvict(r.key)
+			case r.reason.expired():
+				a.metrics.OnExpire(r.key)
+			}
+		}
+		if a.events != nil {
+			if t, ok := removalEvents[r.reason]; ok {
+				v, _, _ := r.value.Value()
+				a.publish(t, r.key, v)
+			}
+		}
+		if a.logger != nil && (r.reason == RemovalEvicted || r.reason.expired()) {
+			a.logger.Log(LevelDebug, "lazy: entry "+r.reason.String(), "key", r.key)
+		}
+		if r.reason == RemovalEvicted && a.onEvict != nil {
+			if v, ok, err := r.value.Value(); ok && err == nil {
+				a.onEvict(r.key, v)
+			}
+		}
+		if r.reason.expired() && a.onExpire != nil {
+			if v, ok, err := r.value.Value(); ok && err == nil {
+				a.onExpire(a.callbackContext(), r.key, v, r.why)
+			}
+		}
+		// Evicted keys were chosen by the policy itself, and expired or swapped keys are
+		// immediately replaced, so only explicit removals need to be passed on.
+		if r.reason == RemovalCleared {
+			if p, ok := a.evictionPolicy.(RemovalAwareEvictionPolicy[K, V]); ok {
+				p.Remove(r.key)
+			}
+		}
+		if a.autoClose {
+			a.close(r.key, r.value)
+		}
+	}
+}
+
+// WithAutoClose returns an Option that closes values implementing io.Closer when their entry
+// leaves the map for any reason: eviction, expiry, Clear, Delete, RemoveWhere or being replaced
+// by a refresh. A value replaced in place, by Put, SetMany or a WithEqual refresh returning a
+// different value, is closed too. Close is called after the entry has been removed and the map lock released,
+// and after any WithEvictionCallback or WithExpiryCallbackCtx. Errors from Close are passed to
+// the handler set with WithCloseErrorHandler, if any, and otherwise ignored. Values shared with
+// another map, for example through Clone or a LoaderGroup, are closed when either map drops them.
+func WithAutoClose[K comparable, V any]() Option[K, V] {
+	return func(a *args[K, V]) { a.autoClose = true }
+}
+
+// WithCloseErrorHandler returns an Option that calls fn with the key and error whenever
+// closing a value under WithAutoClose fails.
+func WithCloseErrorHandler[K comparable, V any](fn func(K, error)) Option[K, V] {
+	return func(a *args[K, V]) { a.onCloseError = fn }
+}
+
+// close closes the value held by lv if it was loaded successfully and implements io.Closer.
+func (a *args[K, V]) close(key K, lv *Value[V]) {
+	if v, ok, err := lv.Value(); ok && err == nil {
+		a.closeValue(key, v)
+	}
+}
+
+// closeDisplaced closes the value of old, a result replaced in place by next, under
+// WithAutoClose. Nothing is closed if old held an error or next is the same value.
+// It must be called without the map lock held.
+func (a *args[K, V]) closeDisplaced(key K, old *result[V], next V) {
+	if !a.autoClose || old == nil || old.err != nil || sameValue(old.value, next) {
+		return
+	}
+	a.closeValue(key, old.value)
+}
+
+// sameValue reports whether x and y are identical. Values that can't be compared count as
+// different.
+func sameValue(x, y any) bool {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	if !vx.IsValid() || !vy.IsValid() {
+		return vx.IsValid() == vy.IsValid()
+	}
+	return vx.Type() == vy.Type() && vx.Comparable() && vy.Comparable() && vx.Equal(vy)
+}
+
+// closeValue closes v if it implements io.Closer.
+func (a *args[K, V]) closeValue(key K, v V) {
+	c, ok := any(v).(io.Closer)
+	if !ok {
+		return
+	}
+	if err := c.Close(); err != nil {
+		if a.logger != nil {
+			a.logger.Log(LevelError, "lazy: closing removed value failed", "key", key, "error", err)
+		}
+		if a.onCloseError != nil {
+			a.onCloseError(key, err)
+		}
+	}
+}
+
+// WithEvictionCallback returns an Option that calls fn whenever an entry is evicted to keep the
+// map within MaxSize, or deleted with LazyMap.Delete or LazyMap.Remove. fn receives the evicted
+// key and its value, read without counting as a use; entries that were never successfully
+// loaded are skipped. It is called after the map lock has been released, so it may call back
+// into the map, e.g. to close resources held by the value.
+func WithEvictionCallback[K comparable, V any](fn func(K, V)) Option[K, V] {
+	return func(a *args[K, V]) { a.onEvict = fn }
+}
+
+// WithExpiryCallbackCtx returns an Option that calls fn whenever an expired entry is removed,
+// whether found on lookup or by the janitor. Like WithEvictionCallback, fn receives the key and
+// value, skipping entries that never loaded successfully, and is called after the map lock has
+// been released. It also receives the context set with WithCallbackContext, or
+// context.Background, so teardown such as closing a connection can respect its deadline, and
+// the reason the entry expired, as reported by ExpiryReason, or "context" or "bumped" for
+// entries expired by GetContext or LazyMap.Bump. Errors cached by WithNegativeCache have no
+// value, so their expiry isn't reported.
+func WithExpiryCallbackCtx[K comparable, V any](fn func(ctx context.Context, key K, value V, reason string)) Option[K, V] {
+	return func(a *args[K, V]) { a.onExpire = fn }
+}
+
+// WithCallbackContext returns an Option that sets the context passed to context-aware
+// callbacks such as WithExpiryCallbackCtx.
+func WithCallbackContext[K comparable, V any](ctx context.Context) Option[K, V] {
+	return func(a *args[K, V]) { a.callbackCtx = ctx }
+}
+
+// callbackContext returns the context for context-aware callbacks.
+func (a *args[K, V]) callbackContext() context.Context {
+	if a.callbackCtx != nil {
+		return a.callbackCtx
+	}
+	return context.Background()
+}
+
+// removalLog is a fixed-size ring buffer of the most recent removals.
+type removalLog[K comparable] struct {
+	mu   sync.Mutex
+	buf  []Removal[K]
+	next int
+	full bool
+}
+
+func (l *removalLog[K]) record(key K, reason RemovalReason, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf[l.next] = Removal[K]{Key: key, Reason: reason, Time: at}
+	l.next++
+	if l.next == len(l.buf) {
+		l.next = 0
+		l.full = true
+	}
+}
+
+// recent returns up to n of the most recent removals, oldest first.
+func (l *removalLog[K]) recent(n int) []Removal[K] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	size := l.next
+	if l.full {
+		size = len(l.buf)
+	}
+	if n > size || n < 0 {
+		n = size
+	}
+	out := make([]Removal[K], n)
+	for i := range out {
+		out[i] = l.buf[(l.next-n+i+len(l.buf))%len(l.buf)]
+	}
+	return out
+}
+
+// WithRemovalLog returns an Option that records the last capacity removals from the map,
+// along with the reason each entry was removed and when, by the map's TimeSource. Use LazyMap.RecentRemovals to read them.
+// The log is held by the Option, so it is shared by every call the Option is passed to.
+func WithRemovalLog[K comparable, V any](capacity int) Option[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	l := &removalLog[K]{buf: make([]Removal[K], capacity)}
+	return func(a *args[K, V]) { a.removalLog = l }
+}
+
+// RecentRemovals returns up to n of the most recent removals, oldest first.
+// It returns nil unless the map was created with WithRemovalLog.
+func (lm *LazyMap[K, V]) RecentRemovals(n int) []Removal[K] {
+	l := lm.config().removalLog
+	if l == nil {
+		return nil
+	}
+	return l.recent(n)
+}
+
+// RemoveWhere removes every entry for which pred returns true and returns how many were removed.
+// pred receives the key and its *Value so it can inspect Value, CreatedAt and so on.
+// It is called with the map's write lock held, so it must not call back into the LazyMap.
+func (lm *LazyMap[K, V]) RemoveWhere(pred func(key K, v *Value[V]) bool) int {
+	a := lm.config()
+	var removals []removal[K, V]
+	lm.mu.Lock()
+	for k, lv := range lm.m {
+		if pred(k, lv) {
+			removals = append(removals, removal[K, V]{key: k, value: lv, reason: RemovalCleared})
+		}
+	}
+	for _, r := range removals {
+		delete(lm.m, r.key)
+	}
+	if len(removals) > 0 {
+		a.republish(lm.m)
+	}
+	lm.mu.Unlock()
+	a.removed(removals)
+	return len(removals)
+}
@@ -0,0 +1,362 @@
+package lazy_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestRemovalLog(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int](
+		lazy.MaxSize[string, int](2),
+		lazy.WithEvictionPolicy[string, int](lazy.NewLRUEvictionPolicy[string, int]()),
+		lazy.WithRemovalLog[string, int](10),
+	)
+	fetch := func(k string) (int, error) { return len(k), nil }
+
+	Must(lm.Get("a", fetch))
+	Must(lm.Get("bb", fetch))
+	// Evicts "a", the least recently used.
+	Must(lm.Get("ccc", fetch))
+	// Replaces "bb".
+	Must(lm.Get("bb", fetch, lazy.Refresh[string, int]()))
+	// Expires "bb" on the next access.
+	Must(lm.Get("bb", fetch, lazy.WithExpiry[string, int](lazy.ExpireAfterUses[int](1))))
+	lm.Remove("ccc")
+	// Removing an absent key records nothing.
+	lm.Remove("missing")
+
+	want := []lazy.Removal[string]{
+		{Key: "a", Reason: lazy.RemovalEvicted},
+		{Key: "bb", Reason: lazy.RemovalSwapped},
+		{Key: "bb", Reason: lazy.RemovalExpired},
+		{Key: "ccc", Reason: lazy.RemovalCleared},
+	}
+	got := lm.RecentRemovals(10)
+	if len(got) != len(want) {
+		t.Fatalf("got %d removals: %v", len(got), got)
+	}
+	for i := range want {
+		if got[i].Key != want[i].Key || got[i].Reason != want[i].Reason || got[i].Time.IsZero() {
+			t.Errorf("removal %d: got %v %v, want %v %v", i, got[i].Key, got[i].Reason, want[i].Key, want[i].Reason)
+		}
+	}
+
+	last := lm.RecentRemovals(1)
+	if len(last) != 1 || last[0].Key != "ccc" {
+		t.Fatalf("last removal %v", last)
+	}
+}
+
+func TestRemovalLogWraps(t *testing.T) {
+	lm := lazy.NewLazyMap[int, int](lazy.WithRemovalLog[int, int](2))
+	for i := 0; i < 5; i++ {
+		lm.Set(i, i)
+		lm.Remove(i)
+	}
+	got := lm.RecentRemovals(10)
+	if len(got) != 2 || got[0].Key != 3 || got[1].Key != 4 {
+		t.Fatalf("got %v", got)
+	}
+	if lazy.NewLazyMap[int, int]().RecentRemovals(1) != nil {
+		t.Fatal("expected nil without a removal log")
+	}
+}
+
+func TestRemovalLogReasonsAndClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := lazy.NewFakeClock(start)
+	lm := lazy.NewLazyMap[string, int](
+		lazy.WithTimeSource[string, int](clock),
+		lazy.WithRemovalLog[string, int](10),
+	)
+	fetch := func(context.Context, string) (int, error) { return 1, nil }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	Must(lm.GetContext(ctx, "scoped", fetch))
+	cancel()
+	clock.Advance(time.Minute)
+	// The scoped entry is released once its context has ended.
+	Must(lm.GetContext(context.Background(), "scoped", fetch))
+	clock.Advance(time.Minute)
+	lm.Bump()
+	Must(lm.GetContext(context.Background(), "scoped", fetch))
+
+	want := []lazy.Removal[string]{
+		{Key: "scoped", Reason: lazy.RemovalReleased, Time: start.Add(time.Minute)},
+		{Key: "scoped", Reason: lazy.RemovalInvalidatedAll, Time: start.Add(2 * time.Minute)},
+	}
+	if got := lm.RecentRemovals(10); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+// removeRecordingPolicy records the keys passed to Remove.
+type removeRecordingPolicy struct {
+	*lazy.LRUEvictionPolicy[int, int]
+	removed []int
+}
+
+func (p *removeRecordingPolicy) Remove(key int) {
+	p.removed = append(p.removed, key)
+	p.LRUEvictionPolicy.Remove(key)
+}
+
+func TestLazyMapRemoveWhere(t *testing.T) {
+	policy := &removeRecordingPolicy{LRUEvictionPolicy: lazy.NewLRUEvictionPolicy[int, int]()}
+	lm := lazy.NewLazyMap[int, int](
+		lazy.MaxSize[int, int](10),
+		lazy.WithEvictionPolicy[int, int](policy),
+	)
+	for i := 0; i < 10; i++ {
+		lm.Set(i, i*10)
+	}
+
+	removed := lm.RemoveWhere(func(k int, v *lazy.Value[int]) bool {
+		val, ok, _ := v.Value()
+		return ok && k%2 == 0 && val == k*10
+	})
+	if removed != 5 {
+		t.Fatalf("removed=%d", removed)
+	}
+	if len(policy.removed) != 5 {
+		t.Fatalf("policy notified of %v", policy.removed)
+	}
+	keys := func() map[int]bool {
+		seen := map[int]bool{}
+		lm.RemoveWhere(func(k int, _ *lazy.Value[int]) bool {
+			seen[k] = true
+			return false
+		})
+		return seen
+	}
+	remaining := keys()
+	for i := 0; i < 10; i++ {
+		if remaining[i] != (i%2 == 1) {
+			t.Fatalf("key %d present=%v", i, remaining[i])
+		}
+	}
+}
+
+func TestEvictionCallback(t *testing.T) {
+	type evicted struct {
+		key   string
+		value int
+	}
+	var got []evicted
+	var lm *lazy.LazyMap[string, int]
+	lm = lazy.NewLazyMap[string, int](
+		lazy.MaxSize[string, int](2),
+		lazy.WithEvictionPolicy[string, int](lazy.NewLRUEvictionPolicy[string, int]()),
+		lazy.WithEvictionCallback[string, int](func(k string, v int) {
+			got = append(got, evicted{k, v})
+			// The map lock has been released, so taking it again doesn't deadlock.
+			lm.RemoveWhere(func(string, *lazy.Value[int]) bool { return false })
+		}),
+	)
+	fetch := func(k string) (int, error) { return len(k), nil }
+
+	Must(lm.Get("a", fetch))
+	Must(lm.Get("bb", fetch))
+	Must(lm.Get("a", fetch))
+	Must(lm.Get("ccc", fetch))
+
+	if len(got) != 1 || got[0] != (evicted{"bb", 2}) {
+		t.Fatalf("got %v", got)
+	}
+	// Deleting a key also reports it, but clearing it via RemoveWhere doesn't.
+	lm.Remove("a")
+	if len(got) != 2 || got[1] != (evicted{"a", 1}) {
+		t.Fatalf("after Remove got %v", got)
+	}
+	lm.RemoveWhere(func(string, *lazy.Value[int]) bool { return true })
+	if len(got) != 2 {
+		t.Fatalf("after RemoveWhere got %v", got)
+	}
+}
+
+func TestLazyMapDelete(t *testing.T) {
+	policy := &removeRecordingPolicy{LRUEvictionPolicy: lazy.NewLRUEvictionPolicy[int, int]()}
+	lm := lazy.NewLazyMap[int, int](lazy.WithEvictionPolicy[int, int](policy))
+	Must(lm.Get(1, func(k int) (int, error) { return k, nil }))
+
+	if !lm.Delete(1) {
+		t.Fatal("Delete of a present key returned false")
+	}
+	if _, ok := lm.GetIfPresent(1); ok {
+		t.Fatal("key still present after Delete")
+	}
+	if lm.Delete(2) {
+		t.Fatal("Delete of an absent key returned true")
+	}
+	if len(policy.removed) != 1 || policy.removed[0] != 1 {
+		t.Fatalf("policy removed %v", policy.removed)
+	}
+}
+
+// fakeCloser counts how many times it is closed.
+type fakeCloser struct {
+	name   string
+	closed atomic.Int32
+	err    error
+}
+
+func (c *fakeCloser) Close() error {
+	c.closed.Add(1)
+	return c.err
+}
+
+func TestWithAutoClose(t *testing.T) {
+	var closeErrs []string
+	lm := lazy.NewLazyMap[string, *fakeCloser](
+		lazy.MaxSize[string, *fakeCloser](1),
+		lazy.WithAutoClose[string, *fakeCloser](),
+		lazy.WithCloseErrorHandler[string, *fakeCloser](func(k string, err error) {
+			closeErrs = append(closeErrs, k+": "+err.Error())
+		}),
+	)
+	fetch := func(k string) (*fakeCloser, error) { return &fakeCloser{name: k}, nil }
+
+	a := Must(lm.Get("a", fetch))
+	// Evicts "a".
+	b := Must(lm.Get("b", func(k string) (*fakeCloser, error) {
+		return &fakeCloser{name: k, err: errors.New("already closed")}, nil
+	}))
+	if n := a.closed.Load(); n != 1 {
+		t.Fatalf("a closed %d times, want 1", n)
+	}
+	if n := b.closed.Load(); n != 0 {
+		t.Fatalf("b closed %d times before removal", n)
+	}
+
+	lm.Remove("b")
+	if n := b.closed.Load(); n != 1 {
+		t.Fatalf("b closed %d times, want 1", n)
+	}
+	if len(closeErrs) != 1 || closeErrs[0] != "b: already closed" {
+		t.Fatalf("close errors = %v", closeErrs)
+	}
+	if n := a.closed.Load(); n != 1 {
+		t.Fatalf("a closed %d times after later removals", n)
+	}
+}
+
+func TestWithAutoCloseReplacedInPlace(t *testing.T) {
+	lm := lazy.NewLazyMap[string, *fakeCloser](lazy.WithAutoClose[string, *fakeCloser]())
+
+	put := &fakeCloser{name: "put"}
+	lm.Put("a", put)
+	lm.Put("a", put)
+	if n := put.closed.Load(); n != 0 {
+		t.Fatalf("Put of the same value closed it %d times", n)
+	}
+	lm.Put("a", &fakeCloser{name: "put again"})
+	if n := put.closed.Load(); n != 1 {
+		t.Fatalf("value replaced by Put closed %d times, want 1", n)
+	}
+
+	many := Must(lm.Get("a", nil))
+	lm.SetMany(map[string]*fakeCloser{"a": {name: "many"}})
+	if n := many.closed.Load(); n != 1 {
+		t.Fatalf("value replaced by SetMany closed %d times, want 1", n)
+	}
+
+	refreshed := Must(lm.Get("a", nil))
+	same := func(string) (*fakeCloser, error) { return refreshed, nil }
+	eq := lazy.WithEqual[string, *fakeCloser](func(x, y *fakeCloser) bool { return x.name == y.name })
+	Must(lm.Get("a", same, lazy.Refresh[string, *fakeCloser](), eq))
+	if n := refreshed.closed.Load(); n != 0 {
+		t.Fatalf("unchanged value closed %d times by a refresh", n)
+	}
+	Must(lm.Get("a", func(string) (*fakeCloser, error) {
+		return &fakeCloser{name: "refreshed"}, nil
+	}, lazy.Refresh[string, *fakeCloser](), eq))
+	if n := refreshed.closed.Load(); n != 1 {
+		t.Fatalf("value replaced by a WithEqual refresh closed %d times, want 1", n)
+	}
+	if v := Must(lm.Get("a", nil)); v.name != "refreshed" || v.closed.Load() != 0 {
+		t.Fatalf("got %s closed %d times, want the open refreshed value", v.name, v.closed.Load())
+	}
+}
+
+type ctxKey struct{}
+
+func TestWithExpiryCallbackCtx(t *testing.T) {
+	clock := lazy.NewFakeClock(time.Now())
+	base := context.WithValue(context.Background(), ctxKey{}, "teardown")
+	type call struct {
+		ctx    context.Context
+		key    string
+		v      int
+		reason string
+	}
+	var calls []call
+	lm := lazy.NewLazyMap[string, int](
+		lazy.WithTimeSource[string, int](clock),
+		lazy.WithExpiry[string, int](lazy.ExpireAfter[int](time.Minute)),
+		lazy.WithCallbackContext[string, int](base),
+		lazy.WithExpiryCallbackCtx(func(ctx context.Context, k string, v int, reason string) {
+			calls = append(calls, call{ctx, k, v, reason})
+		}),
+	)
+	n := 0
+	fetch := func(string) (int, error) {
+		n++
+		return n, nil
+	}
+
+	Must(lm.Get("a", fetch))
+	Must(lm.Get("a", fetch))
+	if len(calls) != 0 {
+		t.Fatalf("Expected no callback before expiry, got %d", len(calls))
+	}
+	clock.Advance(2 * time.Minute)
+	if v := Must(lm.Get("a", fetch)); v != 2 {
+		t.Fatalf("Expected a reload after expiry, got %d", v)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("Expected 1 callback, got %d", len(calls))
+	}
+	c := calls[0]
+	if c.ctx == nil || c.ctx.Value(ctxKey{}) != "teardown" {
+		t.Fatalf("Expected the callback context, got %v", c.ctx)
+	}
+	if c.key != "a" || c.v != 1 || c.reason != "age" {
+		t.Fatalf("Expected the expired entry a=1 due to age, got %s=%d due to %q", c.key, c.v, c.reason)
+	}
+}
+
+func TestExpiryCallbackReason(t *testing.T) {
+	clock := lazy.NewFakeClock(time.Now())
+	var reasons []string
+	lm := lazy.NewLazyMap[string, int](
+		lazy.WithTimeSource[string, int](clock),
+		lazy.WithExpiry[string, int](lazy.ExpireWhenAny(
+			lazy.ExpireAfter[int](time.Minute),
+			lazy.ExpireAfterUses[int](3),
+		)),
+		lazy.WithExpiryCallbackCtx(func(_ context.Context, _ string, _ int, reason string) {
+			reasons = append(reasons, reason)
+		}),
+	)
+	fetch := func(string) (int, error) { return 1, nil }
+
+	// The load and two reads use the value up.
+	for i := 0; i < 4; i++ {
+		Must(lm.Get("a", fetch))
+	}
+	// After the reload, time runs out first.
+	clock.Advance(2 * time.Minute)
+	Must(lm.Get("a", fetch))
+	lm.Bump()
+	Must(lm.Get("a", fetch))
+
+	if want := []string{"uses", "age", "bumped"}; !slices.Equal(reasons, want) {
+		t.Fatalf("Expected reasons %v, got %v", want, reasons)
+	}
+}
@@ -0,0 +1,124 @@
+package lazy
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// WithRetry returns an Option that retries a failing fetch up to attempts times in total,
+// waiting backoff(n) after the nth failed attempt (a nil backoff retries immediately).
+// The retries happen inside the single in-flight load, so concurrent callers for the same key
+// wait on one retry sequence rather than starting their own. If all attempts fail the final
+// error is handled as usual (DefaultValue, Must, ...). When used with MapContext, retrying
+// stops once every caller waiting on the fetch has given up.
+func WithRetry[K comparable, V any](attempts int, backoff func(attempt int) time.Duration) Option[K, V] {
+	return func(a *args[K, V]) {
+		a.retryAttempts = attempts
+		a.retryBackoff = backoff
+	}
+}
+
+// RetryPolicy decides whether and when a failed fetch is retried; see WithRetryPolicy.
+type RetryPolicy interface {
+	// NextDelay is called after the attempt'th attempt (counting from 1) failed with err.
+	// It returns how long to wait before trying again, or false to give up and return err.
+	NextDelay(attempt int, err error) (time.Duration, bool)
+}
+
+// WithRetryPolicy returns an Option that retries failing fetches as directed by policy, which
+// controls the number of attempts, the backoff between them and which errors are worth retrying.
+// Like WithRetry, the retries happen inside the single in-flight load, and under MapContext they
+// stop once every caller waiting on the fetch has given up. It takes precedence over WithRetry.
+func WithRetryPolicy[K comparable, V any](policy RetryPolicy) Option[K, V] {
+	return func(a *args[K, V]) { a.retryPolicy = policy }
+}
+
+// ExponentialRetry is a RetryPolicy that retries every error with exponential backoff and jitter.
+// The delay after the nth failed attempt is chosen at random between half and all of
+// base * 2^(n-1), capped at max, so that callers failing together don't retry in lockstep.
+type ExponentialRetry struct {
+	base, max   time.Duration
+	maxAttempts int
+}
+
+// NewExponentialRetry creates an ExponentialRetry making at most maxAttempts attempts in total.
+func NewExponentialRetry(base, max time.Duration, maxAttempts int) *ExponentialRetry {
+	return &ExponentialRetry{base: base, max: max, maxAttempts: maxAttempts}
+}
+
+func (r *ExponentialRetry) NextDelay(attempt int, err error) (time.Duration, bool) {
+	if attempt >= r.maxAttempts {
+		return 0, false
+	}
+	d := r.base
+	for i := 1; i < attempt && d < r.max; i++ {
+		d *= 2
+	}
+	d = min(d, r.max)
+	if d <= 0 {
+		return 0, true
+	}
+	return d/2 + rand.N(d/2+1), true
+}
+
+// retrying wraps fn so that it is retried as configured by WithRetryPolicy or WithRetry.
+func (a *args[K, V]) retrying(fn func() (V, error)) func() (V, error) {
+	next := a.nextRetryDelay()
+	if next == nil {
+		return fn
+	}
+	return func() (V, error) {
+		for attempt := 1; ; attempt++ {
+			v, err := fn()
+			if err == nil {
+				return v, err
+			}
+			d, ok := next(attempt, err)
+			if !ok || !a.sleep(d) {
+				return v, err
+			}
+		}
+	}
+}
+
+// nextRetryDelay returns the function deciding the delay before each retry, or nil if failed
+// fetches aren't retried.
+func (a *args[K, V]) nextRetryDelay() func(attempt int, err error) (time.Duration, bool) {
+	if a.retryPolicy != nil {
+		return a.retryPolicy.NextDelay
+	}
+	if a.retryAttempts <= 1 {
+		return nil
+	}
+	return func(attempt int, _ error) (time.Duration, bool) {
+		if attempt >= a.retryAttempts {
+			return 0, false
+		}
+		if a.retryBackoff == nil {
+			return 0, true
+		}
+		return a.retryBackoff(attempt), true
+	}
+}
+
+// sleep waits for d, returning false early if the call's context is done.
+func (a *args[K, V]) sleep(d time.Duration) bool {
+	if a.ctx == nil {
+		time.Sleep(d)
+		return true
+	}
+	if a.ctx.Err() != nil {
+		return false
+	}
+	if d <= 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-a.ctx.Done():
+		return false
+	}
+}
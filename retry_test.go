@@ -0,0 +1,131 @@
+package lazy_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestMapWithRetry(t *testing.T) {
+	m := make(map[string]*lazy.Value[int])
+	var mu sync.RWMutex
+	attempts := 0
+	fetch := func(string) (int, error) {
+		attempts++
+		if attempts < 3 {
+			return 0, errors.New("transient")
+		}
+		return 42, nil
+	}
+	var backoffs []int
+	backoff := func(attempt int) time.Duration {
+		backoffs = append(backoffs, attempt)
+		return time.Millisecond << attempt
+	}
+
+	v, err := lazy.Map(&m, &mu, "k", fetch, lazy.WithRetry[string, int](5, backoff))
+	if err != nil || v != 42 {
+		t.Fatalf("got %v %v", v, err)
+	}
+	if attempts < 3 {
+		t.Fatalf("attempts=%d", attempts)
+	}
+	if len(backoffs) != 2 || backoffs[0] != 1 || backoffs[1] != 2 {
+		t.Fatalf("backoffs=%v", backoffs)
+	}
+}
+
+func TestMapWithRetryGivesUp(t *testing.T) {
+	m := make(map[string]*lazy.Value[int])
+	var mu sync.RWMutex
+	attempts := 0
+	fail := errors.New("down")
+	fetch := func(string) (int, error) { attempts++; return 0, fail }
+
+	_, err := lazy.Map(&m, &mu, "k", fetch, lazy.WithRetry[string, int](3, nil))
+	if !errors.Is(err, fail) || attempts != 3 {
+		t.Fatalf("err=%v attempts=%d", err, attempts)
+	}
+}
+
+func TestMapContextStopsRetrying(t *testing.T) {
+	m := make(map[string]*lazy.Value[int])
+	var mu sync.RWMutex
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	fetch := func(ctx context.Context, _ string) (int, error) {
+		attempts++
+		cancel()
+		return 0, errors.New("down")
+	}
+
+	_, err := lazy.MapContext(ctx, &m, &mu, "k", fetch,
+		lazy.WithRetry[string, int](5, func(int) time.Duration { return time.Hour }))
+	if err == nil || attempts != 1 {
+		t.Fatalf("err=%v attempts=%d", err, attempts)
+	}
+}
+
+// recordingRetry retries errors other than fatal, recording each decision it is asked for.
+type recordingRetry struct {
+	fatal    error
+	attempts []int
+}
+
+func (r *recordingRetry) NextDelay(attempt int, err error) (time.Duration, bool) {
+	r.attempts = append(r.attempts, attempt)
+	return time.Millisecond, !errors.Is(err, r.fatal)
+}
+
+func TestMapWithRetryPolicy(t *testing.T) {
+	fatal := errors.New("not found")
+
+	t.Run("retryable errors", func(t *testing.T) {
+		m := make(map[string]*lazy.Value[int])
+		var mu sync.RWMutex
+		policy := &recordingRetry{fatal: fatal}
+		attempts := 0
+		fetch := func(string) (int, error) {
+			attempts++
+			if attempts < 3 {
+				return 0, errors.New("transient")
+			}
+			return 42, nil
+		}
+		v, err := lazy.Map(&m, &mu, "k", fetch, lazy.WithRetryPolicy[string, int](policy))
+		if err != nil || v != 42 || attempts != 3 {
+			t.Fatalf("got %v %v after %d attempts", v, err, attempts)
+		}
+		if len(policy.attempts) != 2 || policy.attempts[0] != 1 || policy.attempts[1] != 2 {
+			t.Fatalf("policy consulted for %v", policy.attempts)
+		}
+	})
+
+	t.Run("non-retryable error", func(t *testing.T) {
+		m := make(map[string]*lazy.Value[int])
+		var mu sync.RWMutex
+		attempts := 0
+		fetch := func(string) (int, error) { attempts++; return 0, fatal }
+		_, err := lazy.Map(&m, &mu, "k", fetch, lazy.WithRetryPolicy[string, int](&recordingRetry{fatal: fatal}))
+		if !errors.Is(err, fatal) || attempts != 1 {
+			t.Fatalf("err=%v attempts=%d", err, attempts)
+		}
+	})
+}
+
+func TestExponentialRetry(t *testing.T) {
+	r := lazy.NewExponentialRetry(10*time.Millisecond, 50*time.Millisecond, 5)
+	for attempt, want := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 40 * time.Millisecond, 4: 50 * time.Millisecond} {
+		d, ok := r.NextDelay(attempt, errors.New("x"))
+		if !ok || d < want/2 || d > want {
+			t.Errorf("attempt %d: delay %v ok %v, want in [%v, %v]", attempt, d, ok, want/2, want)
+		}
+	}
+	if _, ok := r.NextDelay(5, errors.New("x")); ok {
+		t.Error("retried past maxAttempts")
+	}
+}
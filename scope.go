@@ -0,0 +1,68 @@
+package lazy
+
+import "context"
+
+// GetContext is like Get, but fetch receives ctx, the call returns as soon as ctx is done (see
+// MapContext), and a value loaded by this call is only valid for as long as ctx is: once ctx is
+// done the entry counts as expired, and the next read of the key loads it again. This suits
+// request-scoped caches whose entries shouldn't outlive the request that loaded them. A value
+// that was already cached is returned without being tied to ctx. The entry's scope applies on
+// top of any configured Expiry.
+func (lm *LazyMap[K, V]) GetContext(ctx context.Context, key K, fetch func(context.Context, K) (V, error), opts ...Option[K, V]) (V, error) {
+	var lv *Value[V]
+	var scoped func(context.Context, K) (V, error)
+	if fetch != nil {
+		scoped = func(fetchCtx context.Context, k K) (V, error) {
+			// Bind before the result is stored, so it is never visible without its scope.
+			// lv is the entry being loaded, set by WithValueDest before fetch is called.
+			if lv != nil {
+				lv.extOf().scope.Store(&ctx)
+			}
+			return fetch(fetchCtx, k)
+		}
+	}
+	combinedOpts := make([]Option[K, V], 0, len(lm.opts)+len(opts)+1)
+	combinedOpts = append(combinedOpts, lm.opts...)
+	combinedOpts = append(combinedOpts, opts...)
+	combinedOpts = append(combinedOpts, WithValueDest[K, V](&lv))
+	return MapContext(ctx, &lm.m, &lm.mu, key, scoped, combinedOpts...)
+}
+
+// scopeDone reports whether the context the result was loaded under by GetContext is done.
+func (l *Value[T]) scopeDone() bool {
+	e := l.ext.Load()
+	if e == nil {
+		return false
+	}
+	if ctx := e.scope.Load(); ctx != nil {
+		return (*ctx).Err() != nil
+	}
+	return false
+}
+
+// isExpired reports whether the loaded entry lv has expired, either under the configured
+// Expiry, because the context it was loaded for is done, because WithNegativeCache no
+// longer allows its error to be served or because the map has been bumped since it loaded.
+func (a *args[K, V]) isExpired(lv *Value[V]) bool {
+	if lv.scopeDone() || a.negativeExpired(lv) || a.stale(lv) {
+		return true
+	}
+	return a.expiry != nil && a.expiry.IsExpired(lv)
+}
+
+// expiryReason returns why isExpired reports lv as expired: "context" for an entry loaded by
+// GetContext whose context is done, "negative" for an error WithNegativeCache no longer serves,
+// "bumped" for an entry from before LazyMap.Bump and otherwise the Expiry's ExpiryReason.
+func (a *args[K, V]) expiryReason(lv *Value[V]) string {
+	switch {
+	case lv.scopeDone():
+		return "context"
+	case a.negativeExpired(lv):
+		return "negative"
+	case a.stale(lv):
+		return "bumped"
+	case a.expiry != nil:
+		return ExpiryReason(a.expiry, lv)
+	}
+	return "expired"
+}
@@ -0,0 +1,66 @@
+package lazy
+
+// ShardedLazyMap partitions keys across several LazyMaps to reduce lock contention.
+// Each shard has its own mutex, so operations on keys in different shards do not block each other.
+// Options such as MaxSize apply per shard.
+type ShardedLazyMap[K comparable, V any] struct {
+	shards []*LazyMap[K, V]
+	hasher Hasher[K]
+}
+
+// NewShardedLazyMap creates a ShardedLazyMap with n shards (at least 1).
+// The shard for a key is chosen using the Hasher set with WithHasher, or DefaultHasher otherwise.
+// The remaining options are applied to every shard.
+func NewShardedLazyMap[K comparable, V any](n int, opts ...Option[K, V]) *ShardedLazyMap[K, V] {
+	if n < 1 {
+		n = 1
+	}
+	a := buildArgs(opts)
+	hasher := a.hasher
+	if hasher == nil {
+		hasher = DefaultHasher[K]()
+	}
+	if a.initialCapacity > 0 {
+		// Each shard holds its share of the requested capacity.
+		opts = append(opts[:len(opts):len(opts)], WithInitialCapacity[K, V]((a.initialCapacity+n-1)/n))
+	}
+	shards := make([]*LazyMap[K, V], n)
+	for i := range shards {
+		shards[i] = NewLazyMap(opts...)
+	}
+	return &ShardedLazyMap[K, V]{
+		shards: shards,
+		hasher: hasher,
+	}
+}
+
+// shard returns the LazyMap responsible for key.
+func (sm *ShardedLazyMap[K, V]) shard(key K) *LazyMap[K, V] {
+	return sm.shards[sm.hasher(key)%uint64(len(sm.shards))]
+}
+
+// Get retrieves or creates a value for the given key in its shard.
+func (sm *ShardedLazyMap[K, V]) Get(key K, fetch func(K) (V, error), opts ...Option[K, V]) (V, error) {
+	return sm.shard(key).Get(key, fetch, opts...)
+}
+
+// Set manually sets the value for the given key in its shard.
+func (sm *ShardedLazyMap[K, V]) Set(key K, value V) {
+	sm.shard(key).Set(key, value)
+}
+
+// Remove removes the value associated with the key from its shard.
+func (sm *ShardedLazyMap[K, V]) Remove(key K) {
+	sm.shard(key).Remove(key)
+}
+
+// Len returns the total number of entries across all shards.
+func (sm *ShardedLazyMap[K, V]) Len() int {
+	n := 0
+	for _, s := range sm.shards {
+		s.mu.RLock()
+		n += len(s.m)
+		s.mu.RUnlock()
+	}
+	return n
+}
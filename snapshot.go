@@ -0,0 +1,65 @@
+package lazy
+
+// RangeSnapshot calls fn for each loaded, non-errored entry in the map, stopping if fn returns false.
+// The entries are copied under a brief read lock, which is released before fn is first called,
+// so a slow fn doesn't block writers. The trade-off is that fn sees the map as it was when
+// RangeSnapshot was called: entries added, removed or replaced during iteration are not
+// reflected. Iteration order is unspecified, and no usage is recorded.
+func (lm *LazyMap[K, V]) RangeSnapshot(fn func(K, V) bool) {
+	for k, v := range lm.loaded() {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+// Clone returns an independent copy of the map. Each loaded entry is copied into a new Value
+// holding the same value or error, load time, use count, last access time, pin and GetContext
+// scope, so later changes to either map don't affect the other. Entries that haven't finished
+// loading are skipped. Values themselves are copied shallowly: if V is a pointer, slice or map,
+// both maps refer to the same underlying data.
+//
+// The clone is configured with the original's options followed by opts. Options are shared, so
+// by default both maps use the same eviction policy instance, removal log and so on; pass, for
+// example, WithEvictionPolicy with a fresh policy to give the clone its own. A policy set this
+// way starts out tracking every copied key.
+func (lm *LazyMap[K, V]) Clone(opts ...Option[K, V]) *LazyMap[K, V] {
+	combinedOpts := make([]Option[K, V], 0, len(lm.opts)+len(opts))
+	combinedOpts = append(combinedOpts, lm.opts...)
+	combinedOpts = append(combinedOpts, opts...)
+	clone := NewLazyMap(combinedOpts...)
+
+	lm.mu.RLock()
+	for k, lv := range lm.m {
+		if r := lv.val.Load(); r != nil {
+			clone.m[k] = lv.clone(r)
+		}
+	}
+	lm.mu.RUnlock()
+	clone.config().republish(clone.m)
+
+	if len(opts) > 0 && clone.defaults.evictionPolicy != nil && clone.defaults.evictionPolicy != lm.config().evictionPolicy {
+		for k := range clone.m {
+			insert(clone.defaults.evictionPolicy, k)
+			clone.defaults.evictionPolicy.Access(k)
+		}
+	}
+	return clone
+}
+
+// clone returns a new Value holding r with l's usage metadata.
+func (l *Value[T]) clone(r *result[T]) *Value[T] {
+	nv := &Value[T]{}
+	nv.flags.Store(l.flags.Load() &^ (flagRefreshing | flagInLoad))
+	if e := l.ext.Load(); e != nil {
+		ne := &valueExt[T]{clock: e.clock}
+		// A result loaded by GetContext is only valid while its context is, in either map.
+		ne.scope.Store(e.scope.Load())
+		nv.ext.Store(ne)
+	}
+	// Results are immutable, so the clone can share r.
+	nv.val.Store(r)
+	nv.uses.Store(l.uses.Load())
+	nv.lastAccess.Store(l.lastAccess.Load())
+	return nv
+}
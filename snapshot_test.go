@@ -0,0 +1,128 @@
+package lazy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestLazyMapRangeSnapshot(t *testing.T) {
+	lm := lazy.NewLazyMap[int, int]()
+	for i := 0; i < 10; i++ {
+		lm.Set(i, i*10)
+	}
+
+	seen := map[int]int{}
+	lm.RangeSnapshot(func(k, v int) bool {
+		if len(seen) == 0 {
+			// Writers must not be blocked while fn runs.
+			done := make(chan struct{})
+			go func() {
+				lm.Set(100, 1000)
+				lm.Remove(0)
+				lm.Remove(1)
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("writer blocked by RangeSnapshot")
+			}
+		}
+		seen[k] = v
+		return true
+	})
+
+	if len(seen) != 10 {
+		t.Fatalf("snapshot had %d entries: %v", len(seen), seen)
+	}
+	for i := 0; i < 10; i++ {
+		if seen[i] != i*10 {
+			t.Fatalf("seen[%d]=%d", i, seen[i])
+		}
+	}
+	if _, ok := seen[100]; ok {
+		t.Fatal("snapshot reflected a concurrent insert")
+	}
+
+	count := 0
+	lm.RangeSnapshot(func(int, int) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Fatalf("iteration didn't stop, count=%d", count)
+	}
+}
+
+func TestLazyMapClone(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	fetch := func(k string) (int, error) { return len(k), nil }
+	Must(lm.Get("a", fetch))
+	Must(lm.Get("bb", fetch))
+	boom := errors.New("boom")
+	_, _ = lm.Get("bad", func(string) (int, error) { return 0, boom })
+
+	clone := lm.Clone()
+
+	lm.Put("a", 100)
+	lm.Remove("bb")
+	Must(lm.Get("ccc", fetch))
+
+	if v, ok := clone.GetIfPresent("a"); !ok || v != 1 {
+		t.Fatalf("clone a = %v %v", v, ok)
+	}
+	if v, ok := clone.GetIfPresent("bb"); !ok || v != 2 {
+		t.Fatalf("clone bb = %v %v", v, ok)
+	}
+	if _, ok := clone.GetIfPresent("ccc"); ok {
+		t.Fatal("clone has key added to the original")
+	}
+	if e, err := clone.GetEntry("bad", fetch); err != nil || !errors.Is(e.Err, boom) {
+		t.Fatalf("clone bad = %+v %v", e, err)
+	}
+
+	// Changes to the clone don't reach the original either.
+	clone.Put("bb", 200)
+	if _, ok := lm.GetIfPresent("bb"); ok {
+		t.Fatal("original has key set on the clone")
+	}
+}
+
+func TestLazyMapCloneKeepsScope(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	Must(lm.GetContext(ctx, "a", func(context.Context, string) (int, error) { return 1, nil }))
+
+	clone := lm.Clone()
+	if v, ok := clone.GetIfPresent("a"); !ok || v != 1 {
+		t.Fatalf("clone a = %v %v before the scope ended", v, ok)
+	}
+	cancel()
+	if v := Must(clone.Get("a", func(string) (int, error) { return 2, nil })); v != 2 {
+		t.Fatalf("clone a = %d, want it reloaded once the scope ended", v)
+	}
+}
+
+func TestLazyMapCloneFreshPolicy(t *testing.T) {
+	lm := lazy.NewLazyMap[int, int](
+		lazy.MaxSize[int, int](2),
+		lazy.WithEvictionPolicy[int, int](lazy.NewLRUEvictionPolicy[int, int]()),
+	)
+	fetch := func(k int) (int, error) { return k, nil }
+	Must(lm.Get(1, fetch))
+	Must(lm.Get(2, fetch))
+
+	policy := lazy.NewLRUEvictionPolicy[int, int]()
+	clone := lm.Clone(lazy.WithEvictionPolicy[int, int](policy))
+	if got := policy.Order(); len(got) != 2 {
+		t.Fatalf("fresh policy tracks %v", got)
+	}
+	Must(clone.Get(3, fetch))
+	if clone.Len() != 2 || lm.Len() != 2 {
+		t.Fatalf("clone len %d, original len %d", clone.Len(), lm.Len())
+	}
+}
@@ -0,0 +1,55 @@
+package lazy
+
+// Source describes where a value returned by LazyMap.GetWithSource came from.
+type Source int
+
+const (
+	// SourceCache means the value was already cached.
+	SourceCache Source = iota + 1
+	// SourceFetch means the value, or error, came from running the fetch function, either by
+	// this call or by a concurrent one it waited for.
+	SourceFetch
+	// SourceDefault means nothing was fetched or the fetch failed, and the default value (see
+	// DefaultValue and WithDefaultFunc), or the zero value, was returned.
+	SourceDefault
+	// SourceSet means the value was stored by the call itself, through the Set option.
+	SourceSet
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceCache:
+		return "cache"
+	case SourceFetch:
+		return "fetch"
+	case SourceDefault:
+		return "default"
+	case SourceSet:
+		return "set"
+	}
+	return "unknown"
+}
+
+// sourceDest returns an Option that makes Map report where the returned value came from.
+func sourceDest[K comparable, V any](s *Source) Option[K, V] {
+	return func(a *args[K, V]) { a.sourceDest = s }
+}
+
+// from records s as the source of the value being returned, if requested.
+func (a *args[K, V]) from(s Source) {
+	if a.sourceDest != nil {
+		*a.sourceDest = s
+	}
+}
+
+// GetWithSource is like Get, but also reports whether the value came from the cache, a fetch,
+// a default or a Set option, e.g. for request tracing.
+func (lm *LazyMap[K, V]) GetWithSource(key K, fetch func(K) (V, error), opts ...Option[K, V]) (V, Source, error) {
+	var src Source
+	combinedOpts := make([]Option[K, V], 0, len(lm.opts)+len(opts)+1)
+	combinedOpts = append(combinedOpts, lm.opts...)
+	combinedOpts = append(combinedOpts, opts...)
+	combinedOpts = append(combinedOpts, sourceDest[K, V](&src))
+	v, err := Map(&lm.m, &lm.mu, key, fetch, combinedOpts...)
+	return v, src, err
+}
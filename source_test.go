@@ -0,0 +1,37 @@
+package lazy_test
+
+import (
+	"errors"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestLazyMapGetWithSource(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	fetch := func(string) (int, error) { return 1, nil }
+
+	check := func(name string, wantV int, wantSrc lazy.Source, v int, src lazy.Source, err error) {
+		t.Helper()
+		if err != nil || v != wantV || src != wantSrc {
+			t.Fatalf("%s: expected %d from %v, got %d from %v (err %v)", name, wantV, wantSrc, v, src, err)
+		}
+	}
+
+	v, src, err := lm.GetWithSource("a", fetch)
+	check("first", 1, lazy.SourceFetch, v, src, err)
+	v, src, err = lm.GetWithSource("a", fetch)
+	check("second", 1, lazy.SourceCache, v, src, err)
+
+	failing := func(string) (int, error) { return 0, errors.New("boom") }
+	v, src, err = lm.GetWithSource("b", failing, lazy.DefaultValue[string, int](7))
+	check("default", 7, lazy.SourceDefault, v, src, err)
+
+	v, src, err = lm.GetWithSource("c", nil, lazy.Set[string, int](3))
+	check("set", 3, lazy.SourceSet, v, src, err)
+
+	v, src, err = lm.GetWithSource("d", failing)
+	if err == nil || src != lazy.SourceFetch {
+		t.Fatalf("failed fetch: expected an error from %v, got %d from %v (err %v)", lazy.SourceFetch, v, src, err)
+	}
+}
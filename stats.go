@@ -0,0 +1,88 @@
+package lazy
+
+import "sync/atomic"
+
+// Stats is a snapshot of a LazyMap's counters, as returned by LazyMap.Stats.
+type Stats struct {
+	// Hits counts lookups served from the cache.
+	Hits int64
+	// Misses counts lookups that couldn't be served from the cache, whether or not they fetched.
+	Misses int64
+	// Evictions counts entries evicted to keep the map within MaxSize.
+	Evictions int64
+	// Expirations counts entries removed because they had expired.
+	Expirations int64
+}
+
+// HitRatio returns the fraction of lookups that were hits, or 0 if there were none.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// add returns the sum of s and o.
+func (s Stats) add(o Stats) Stats {
+	return Stats{
+		Hits:        s.Hits + o.Hits,
+		Misses:      s.Misses + o.Misses,
+		Evictions:   s.Evictions + o.Evictions,
+		Expirations: s.Expirations + o.Expirations,
+	}
+}
+
+// statsCounters holds the live counters behind Stats. Every field is updated atomically,
+// so they can be read without the map lock.
+type statsCounters struct {
+	hits        atomic.Int64
+	misses      atomic.Int64
+	evictions   atomic.Int64
+	expirations atomic.Int64
+}
+
+func (c *statsCounters) snapshot() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	return Stats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+	}
+}
+
+// withStats returns an Option that counts cache events in c. NewLazyMap adds it so that
+// per-call options, which are built afresh, count into the same counters as the defaults.
+func withStats[K comparable, V any](c *statsCounters) Option[K, V] {
+	return func(a *args[K, V]) { a.stats = c }
+}
+
+// Stats returns the map's hit, miss, eviction and expiration counts since it was created.
+// The counters are read individually, so a snapshot taken during concurrent use may not
+// correspond to a single instant. A zero LazyMap, not created with NewLazyMap, has no counters
+// and always reports zero.
+func (lm *LazyMap[K, V]) Stats() Stats {
+	return lm.config().stats.snapshot()
+}
+
+// Stats returns the sum of every shard's Stats.
+func (sm *ShardedLazyMap[K, V]) Stats() Stats {
+	var total Stats
+	for _, s := range sm.shards {
+		total = total.add(s.Stats())
+	}
+	return total
+}
+
+// PerShardStats returns each shard's Stats, in shard order. Shards with far more traffic
+// than the rest suggest the Hasher spreads keys poorly.
+func (sm *ShardedLazyMap[K, V]) PerShardStats() []Stats {
+	stats := make([]Stats, len(sm.shards))
+	for i, s := range sm.shards {
+		stats[i] = s.Stats()
+	}
+	return stats
+}
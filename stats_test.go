@@ -0,0 +1,55 @@
+package lazy_test
+
+import (
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestLazyMapStats(t *testing.T) {
+	lm := lazy.NewLazyMap[int, int](lazy.MaxSize[int, int](2))
+	fetch := func(k int) (int, error) { return k, nil }
+
+	for _, k := range []int{1, 1, 2, 2, 3} {
+		Must(lm.Get(k, fetch))
+	}
+	// Per-call options count into the same counters.
+	Must(lm.Get(3, fetch, lazy.DontFetch[int, int]()))
+
+	want := lazy.Stats{Hits: 3, Misses: 3, Evictions: 1}
+	if got := lm.Stats(); got != want {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+	if r := lm.Stats().HitRatio(); r != 0.5 {
+		t.Fatalf("Expected a hit ratio of 0.5, got %v", r)
+	}
+}
+
+func TestShardedLazyMapStats(t *testing.T) {
+	sm := lazy.NewShardedLazyMap[int, int](4)
+	fetch := func(k int) (int, error) { return k, nil }
+
+	for k := 0; k < 100; k++ {
+		Must(sm.Get(k, fetch))
+	}
+	for k := 0; k < 50; k++ {
+		Must(sm.Get(k, fetch))
+	}
+
+	perShard := sm.PerShardStats()
+	if len(perShard) != 4 {
+		t.Fatalf("Expected 4 shards, got %d", len(perShard))
+	}
+	var hits, misses int64
+	for _, s := range perShard {
+		hits += s.Hits
+		misses += s.Misses
+	}
+	total := sm.Stats()
+	if total.Hits != 50 || total.Misses != 100 {
+		t.Fatalf("Expected 50 hits and 100 misses, got %+v", total)
+	}
+	if total.Hits != hits || total.Misses != misses {
+		t.Fatalf("Expected the aggregate to match the per-shard sum, got %+v vs %d hits, %d misses", total, hits, misses)
+	}
+}
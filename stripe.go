@@ -0,0 +1,33 @@
+package lazy
+
+// WithStripedLocks returns an Option that makes the map's Values share n load locks, picked by
+// hashing each key with the map's Hasher (see WithHasher), instead of each Value carrying locks
+// of its own. This saves memory in very large caches, at the cost of contention: loads of two
+// keys in the same stripe run one after the other, even though they are unrelated, so a slow
+// fetch holds up the other keys of its stripe. Pick n well above the number of fetches
+// expected to run at once.
+//
+// A fetch that loads another key of the same map in its own stripe deadlocks, unless the map
+// also uses WithRecursionGuard, in which case the key loads inline on the fetch's goroutine.
+// Even then, two fetches running at once, each loading a key in the other's stripe, wait for
+// each other forever, so maps whose fetches load other keys are better off without striping,
+// or with a large n. Values that join the map other than by loading, such
+// as through Clone, keep locks of their own.
+//
+// The stripes are held by the Option, so they are shared by every call the Option is passed to.
+func WithStripedLocks[K comparable, V any](n int) Option[K, V] {
+	if n < 1 {
+		n = 1
+	}
+	stripes := make([]loadLocks, n)
+	return func(a *args[K, V]) { a.stripes = stripes }
+}
+
+// stripe returns the load locks for key.
+func (a *args[K, V]) stripe(key K) *loadLocks {
+	hash := a.hasher
+	if hash == nil {
+		hash = defaultHash[K]
+	}
+	return &a.stripes[hash(key)%uint64(len(a.stripes))]
+}
@@ -0,0 +1,96 @@
+package lazy_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestWithStripedLocksConcurrent(t *testing.T) {
+	lm := lazy.NewLazyMap[int, string](lazy.WithStripedLocks[int, string](4))
+	var fetches [64]atomic.Int32
+	fetch := func(k int) (string, error) {
+		fetches[k].Add(1)
+		time.Sleep(time.Microsecond)
+		return strconv.Itoa(k), nil
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				k := (g + i) % len(fetches)
+				if v, err := lm.Get(k, fetch); err != nil || v != strconv.Itoa(k) {
+					t.Errorf("Get(%d) = %q, %v", k, v, err)
+					return
+				}
+				if i%50 == 0 {
+					lm.Set(k, strconv.Itoa(k))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for k := range fetches {
+		if n := fetches[k].Load(); n != 1 {
+			t.Fatalf("key %d fetched %d times, want 1", k, n)
+		}
+	}
+}
+
+func TestWithStripedLocksNestedLoad(t *testing.T) {
+	// With a single stripe every key shares the lock the outer fetch holds.
+	lm := lazy.NewLazyMap[string, int](
+		lazy.WithStripedLocks[string, int](1),
+		lazy.WithRecursionGuard[string, int](),
+	)
+	var inner error
+	var fetch func(string) (int, error)
+	fetch = func(k string) (int, error) {
+		switch k {
+		case "a":
+			b, err := lm.Get("b", fetch)
+			if err != nil {
+				return 0, err
+			}
+			c, err := lm.GetContext(context.Background(), "c", func(ctx context.Context, k string) (int, error) {
+				return fetch(k)
+			})
+			if err != nil {
+				return 0, err
+			}
+			_, inner = lm.Get("a", fetch)
+			return b + c, nil
+		case "b":
+			return 1, nil
+		}
+		return 2, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if v, err := lm.Get("a", fetch); err != nil || v != 3 {
+			t.Errorf("Get(a) = %v, %v", v, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("nested load in the same stripe deadlocked")
+	}
+	if !errors.Is(inner, lazy.ErrRecursiveLoad) {
+		t.Fatalf("inner error = %v, want ErrRecursiveLoad", inner)
+	}
+	if v := Must(lm.Get("c", nil, lazy.DontFetch[string, int]())); v != 2 {
+		t.Fatalf("Expected the nested load to be cached, got %d", v)
+	}
+}
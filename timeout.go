@@ -0,0 +1,46 @@
+package lazy
+
+import (
+	"context"
+	"time"
+)
+
+// WithTimeout returns an Option that bounds how long a Map call waits for a cold load. If the
+// load hasn't finished within d the call returns ErrLoadTimeout, and by default the load keeps
+// running in the background: its result is cached and later callers wait on it rather than
+// starting another. Use AbandonOnTimeout to abandon the load instead. Cache hits never time out.
+// Under MapContext a timeout is handled like the caller's context being canceled.
+func WithTimeout[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(a *args[K, V]) { a.timeout = d }
+}
+
+// AbandonOnTimeout returns an Option that makes a load that times out under WithTimeout be
+// abandoned once every caller waiting on it has timed out: a failure is not cached, and the next
+// caller starts a fresh load instead of joining it. The fetch function can't be stopped, so a
+// value it goes on to return successfully is still cached.
+func AbandonOnTimeout[K comparable, V any]() Option[K, V] {
+	return func(a *args[K, V]) { a.abandonOnTimeout = true }
+}
+
+// waitContext returns the context a load is waited on with and the function that cancels the
+// load's fetch, applying any WithTimeout. The returned stop function must be called once the
+// wait is over. ok is false if the load isn't waited on with a context at all.
+func (a *args[K, V]) waitContext() (ctx context.Context, cancelFetch context.CancelFunc, stop context.CancelFunc, ok bool) {
+	ctx, cancelFetch, stop = a.waitCtx, a.cancelFetch, func() {}
+	if ctx == nil {
+		if a.timeout <= 0 {
+			return nil, nil, nil, false
+		}
+		ctx, cancelFetch = context.Background(), func() {}
+	}
+	if a.timeout > 0 {
+		ctx, stop = context.WithTimeout(ctx, a.timeout)
+	}
+	return ctx, cancelFetch, stop, true
+}
+
+// lingers reports whether a load should keep running for later callers once every caller
+// waiting on it has given up.
+func (a *args[K, V]) lingers() bool {
+	return a.waitCtx == nil && a.timeout > 0 && !a.abandonOnTimeout
+}
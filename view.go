@@ -0,0 +1,105 @@
+package lazy
+
+// ReadOnlyView is a read-only handle to a LazyMap.
+// It shares the map and mutex of the LazyMap it came from, so it always reflects live updates,
+// but it has no methods that fetch, set or remove entries. Hand it to consumers that should
+// only read what a producer has loaded.
+type ReadOnlyView[K comparable, V any] struct {
+	lm *LazyMap[K, V]
+}
+
+// ReadOnly returns a read-only view of the map.
+func (lm *LazyMap[K, V]) ReadOnly() ReadOnlyView[K, V] {
+	return ReadOnlyView[K, V]{lm: lm}
+}
+
+// Get returns the cached value for key without fetching, like LazyMap.Get with DontFetch.
+// If the key is not loaded, or has expired, it returns the map's DefaultValue if one is set,
+// otherwise the zero value. Unlike DontFetch it never adds an entry for a missing key.
+// A hit counts as an access for usage tracking and the eviction policy.
+func (view ReadOnlyView[K, V]) Get(key K) (V, error) {
+	cfg := view.lm.config()
+	view.lm.mu.RLock()
+	lv, ok := view.lm.m[key]
+	view.lm.mu.RUnlock()
+	if ok && lv.IsLoaded() && !cfg.isExpired(lv) {
+		v, _ := lv.Peek()
+		if cfg.evictionPolicy != nil {
+			cfg.evictionPolicy.Access(key)
+		}
+		cfg.hit(key)
+		return v, nil
+	}
+	cfg.miss(key)
+	if cfg.defaultValue != nil {
+		return *cfg.defaultValue, nil
+	}
+	var zero V
+	return zero, nil
+}
+
+// Peek returns the cached value for key and whether it is loaded.
+// It does not count as an access, and it ignores expiry.
+func (view ReadOnlyView[K, V]) Peek(key K) (V, bool) {
+	view.lm.mu.RLock()
+	lv, ok := view.lm.m[key]
+	view.lm.mu.RUnlock()
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	v, loaded, _ := lv.Value()
+	return v, loaded
+}
+
+// Len returns the number of entries in the map.
+func (view ReadOnlyView[K, V]) Len() int {
+	return view.lm.Len()
+}
+
+// Keys returns the keys currently in the map, in no particular order.
+func (view ReadOnlyView[K, V]) Keys() []K {
+	return view.lm.Keys()
+}
+
+// Len returns the number of entries in the map.
+func (lm *LazyMap[K, V]) Len() int {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+	return len(lm.m)
+}
+
+// Keys returns the keys currently in the map, in no particular order.
+func (lm *LazyMap[K, V]) Keys() []K {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+	keys := make([]K, 0, len(lm.m))
+	for k := range lm.m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Errors returns the keys whose values are currently cached with a fetch error, along with
+// those errors. It is intended for health checks and dashboards, and doesn't count as a use.
+func (lm *LazyMap[K, V]) Errors() map[K]error {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+	errs := make(map[K]error)
+	for k, lv := range lm.m {
+		if err := lv.Err(); err != nil {
+			errs[k] = err
+		}
+	}
+	return errs
+}
+
+// ApproxSize returns the sum of weigh over every loaded, non-errored value, as a rough estimate
+// of the memory the cache holds, e.g. with weigh returning a value's length in bytes. Entries
+// that aren't loaded or hold an error count as zero. weigh is called with the read lock held,
+// so it must not call back into the LazyMap. It doesn't count as a use.
+func (lm *LazyMap[K, V]) ApproxSize(weigh func(V) int64) int64 {
+	var total int64
+	lm.eachLoaded(func(v V) { total += weigh(v) })
+	return total
+}
@@ -0,0 +1,72 @@
+package lazy_test
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestReadOnlyViewReflectsWrites(t *testing.T) {
+	lm := lazy.NewLazyMap[string, int]()
+	view := lm.ReadOnly()
+
+	if v, err := view.Get("a"); err != nil || v != 0 {
+		t.Fatalf("missing key got %v %v", v, err)
+	}
+	if view.Len() != 0 {
+		t.Fatalf("Get on a view added an entry, Len=%d", view.Len())
+	}
+
+	lm.Set("a", 1)
+	_, _ = lm.Get("b", func(string) (int, error) { return 2, nil })
+
+	if v, err := view.Get("a"); err != nil || v != 1 {
+		t.Fatalf("Get(a) got %v %v", v, err)
+	}
+	if v, ok := view.Peek("b"); !ok || v != 2 {
+		t.Fatalf("Peek(b) got %v %v", v, ok)
+	}
+	keys := view.Keys()
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("Keys=%v", keys)
+	}
+
+	lm.Remove("a")
+	if _, ok := view.Peek("a"); ok {
+		t.Fatal("view still sees removed key")
+	}
+	if view.Len() != 1 {
+		t.Fatalf("Len=%d", view.Len())
+	}
+}
+
+func TestReadOnlyViewHasNoMutators(t *testing.T) {
+	var view any = lazy.NewLazyMap[string, int]().ReadOnly()
+	if _, ok := view.(interface{ Set(string, int) }); ok {
+		t.Fatal("view has Set")
+	}
+	if _, ok := view.(interface{ Remove(string) }); ok {
+		t.Fatal("view has Remove")
+	}
+}
+
+func TestLazyMapApproxSize(t *testing.T) {
+	lm := lazy.NewLazyMap[string, string]()
+	for _, k := range []string{"a", "bb", "cccc"} {
+		Must(lm.Get(k, func(k string) (string, error) { return strings.Repeat(k, 2), nil }))
+	}
+	_, _ = lm.Get("bad", func(string) (string, error) { return "ignored", errors.New("down") })
+	lm.Pin("placeholder")
+
+	size := lm.ApproxSize(func(v string) int64 { return int64(len(v)) })
+	if size != 14 {
+		t.Fatalf("size = %d, want 14", size)
+	}
+	if e, _ := lm.GetEntry("a", nil); e.Uses != 2 {
+		t.Fatalf("ApproxSize counted as a use: uses=%d", e.Uses)
+	}
+}
@@ -0,0 +1,62 @@
+package lazy
+
+import (
+	"errors"
+	"sync"
+)
+
+// WithConcurrency returns an Option that limits how many keys LoadAll fetches at once.
+// A value of zero or less means no limit.
+func WithConcurrency[K comparable, V any](n int) Option[K, V] {
+	return func(a *args[K, V]) { a.concurrency = n }
+}
+
+// LoadAll loads every key concurrently so that later Gets are cache hits.
+// Keys that are already loaded are skipped. Each key has its own Value lock, so distinct
+// keys load in parallel, bounded by WithConcurrency if set. The map's default options apply,
+// followed by opts. The errors from all failed keys are joined and returned.
+func (lm *LazyMap[K, V]) LoadAll(keys []K, fetch func(K) (V, error), opts ...Option[K, V]) error {
+	combinedOpts := make([]Option[K, V], 0, len(lm.opts)+len(opts))
+	combinedOpts = append(combinedOpts, lm.opts...)
+	combinedOpts = append(combinedOpts, opts...)
+	a := buildArgs(combinedOpts)
+
+	// Skip loaded keys up front so warming doesn't count as a use of them.
+	pending := make([]K, 0, len(keys))
+	lm.mu.RLock()
+	for _, key := range keys {
+		if lv, ok := lm.m[key]; !ok || !lv.IsLoaded() {
+			pending = append(pending, key)
+		}
+	}
+	lm.mu.RUnlock()
+
+	var sem chan struct{}
+	if a.concurrency > 0 {
+		sem = make(chan struct{}, a.concurrency)
+	}
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, key := range pending {
+		if sem != nil {
+			sem <- struct{}{}
+		}
+		wg.Add(1)
+		go func(key K) {
+			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+			if _, err := mapWith(&lm.m, &lm.mu, key, fetch, a); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(key)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,150 @@
+package lazy_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lazy "github.com/arran4/go-be-lazy"
+)
+
+func TestLazyMapLoadAll(t *testing.T) {
+	lm := lazy.NewLazyMap[int, int]()
+	lm.Set(0, -1)
+
+	var calls, running, peak atomic.Int32
+	fetch := func(k int) (int, error) {
+		calls.Add(1)
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return k * 2, nil
+	}
+
+	keys := make([]int, 100)
+	for i := range keys {
+		keys[i] = i
+	}
+	if err := lm.LoadAll(keys, fetch, lazy.WithConcurrency[int, int](8)); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 99 {
+		t.Fatalf("expected the preloaded key to be skipped, fetched %d", n)
+	}
+	if p := peak.Load(); p > 8 {
+		t.Fatalf("concurrency limit exceeded: %d", p)
+	}
+
+	for _, k := range keys {
+		want := k * 2
+		if k == 0 {
+			want = -1
+		}
+		v, err := lm.Get(k, nil, lazy.DontFetch[int, int](), lazy.MustBeCached[int, int]())
+		if err != nil || v != want {
+			t.Fatalf("key %d: got %v %v", k, v, err)
+		}
+	}
+}
+
+func TestLazyMapLoadAllErrors(t *testing.T) {
+	lm := lazy.NewLazyMap[int, int]()
+	errOdd := errors.New("odd")
+	err := lm.LoadAll([]int{1, 2, 3}, func(k int) (int, error) {
+		if k%2 == 1 {
+			return 0, errOdd
+		}
+		return k, nil
+	})
+	if !errors.Is(err, errOdd) {
+		t.Fatalf("err=%v", err)
+	}
+	if v, err := lm.Get(2, nil, lazy.DontFetch[int, int]()); err != nil || v != 2 {
+		t.Fatalf("got %v %v", v, err)
+	}
+}
+
+func TestLazyMapWithInitialCapacity(t *testing.T) {
+	const n = 1000
+	lm := lazy.NewLazyMap[int, int](lazy.WithInitialCapacity[int, int](n))
+	for i := 0; i < n; i++ {
+		lm.Set(i, i)
+	}
+	if lm.Len() != n {
+		t.Fatalf("Len=%d", lm.Len())
+	}
+	sm := lazy.NewShardedLazyMap[int, int](4, lazy.WithInitialCapacity[int, int](n))
+	for i := 0; i < n; i++ {
+		sm.Set(i, i)
+	}
+	if sm.Len() != n {
+		t.Fatalf("sharded Len=%d", sm.Len())
+	}
+}
+
+func BenchmarkLazyMapWarmUp(b *testing.B) {
+	const n = 10000
+	values := make(map[int]int, n)
+	for i := 0; i < n; i++ {
+		values[i] = i
+	}
+	b.Run("NoHint", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			lazy.NewLazyMap[int, int]().SetMany(values)
+		}
+	})
+	b.Run("WithInitialCapacity", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			lazy.NewLazyMap[int, int](lazy.WithInitialCapacity[int, int](n)).SetMany(values)
+		}
+	})
+}
+
+func TestWithHighWaterMark(t *testing.T) {
+	var fired []int
+	lm := lazy.NewLazyMap[int, int](
+		lazy.MaxSize[int, int](10),
+		lazy.WithHighWaterMark[int, int](0.8, func(size, max int) {
+			if max != 10 {
+				t.Errorf("max = %d", max)
+			}
+			fired = append(fired, size)
+		}),
+	)
+	fetch := func(k int) (int, error) { return k, nil }
+	for k := 1; k <= 7; k++ {
+		Must(lm.Get(k, fetch))
+	}
+	if len(fired) != 0 {
+		t.Fatalf("fired early: %v", fired)
+	}
+	Must(lm.Get(8, fetch))
+	if len(fired) != 1 || fired[0] != 8 {
+		t.Fatalf("fired = %v, want [8]", fired)
+	}
+	// Further inserts above the mark, including ones that evict, don't fire again.
+	for k := 9; k <= 15; k++ {
+		Must(lm.Get(k, fetch))
+	}
+	if len(fired) != 1 {
+		t.Fatalf("fired again above the mark: %v", fired)
+	}
+
+	// Dropping below the mark re-arms it.
+	for _, k := range lm.Keys()[:5] {
+		lm.Delete(k)
+	}
+	for k := 100; lm.Len() < 8; k++ {
+		Must(lm.Get(k, fetch))
+	}
+	if len(fired) != 2 || fired[1] != 8 {
+		t.Fatalf("fired = %v after re-arming", fired)
+	}
+}
@@ -0,0 +1,37 @@
+package lazy
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// WithHighWaterMark returns an Option that calls fn when an insert brings the map to at least
+// fraction of MaxSize entries, as an early warning that the cache may be undersized. fn is told
+// the size after the insert and MaxSize. It fires once per crossing: further inserts above the
+// mark don't call it again until an insert finds the map below the mark once more. fn is called
+// after the map lock has been released. Without a MaxSize it never fires. The state is held by
+// the Option, so it is shared by every call the Option is passed to.
+func WithHighWaterMark[K comparable, V any](fraction float64, fn func(size, max int)) Option[K, V] {
+	hw := &highWaterMark{fraction: fraction, fn: fn}
+	return func(a *args[K, V]) { a.highWater = hw }
+}
+
+// highWaterMark tracks whether the map is above its mark; see WithHighWaterMark.
+type highWaterMark struct {
+	fraction float64
+	fn       func(size, max int)
+	above    atomic.Bool
+}
+
+// inserted records that an insert left the map with size entries out of max, reporting
+// whether it crossed the mark. It must be called with the map lock held.
+func (hw *highWaterMark) inserted(size, max int) bool {
+	if hw == nil || max <= 0 {
+		return false
+	}
+	if size < int(math.Ceil(hw.fraction*float64(max))) {
+		hw.above.Store(false)
+		return false
+	}
+	return hw.above.CompareAndSwap(false, true)
+}